@@ -0,0 +1,55 @@
+package lex
+
+// LexAll scans the whole input and returns all the emitted tokens
+func LexAll(input string, state StateFn, opts ...Option) []Token {
+	return appendTokens(nil, LexString(input, state, opts...))
+}
+
+// MultiPass scans the input once per state in passes, e.g. a pass for comments
+// and another for code, and returns the tokens of each pass in the same order.
+// Passes run in the caller's goroutine, independently of each other
+func MultiPass(input string, passes []StateFn, opts ...Option) [][]Token {
+	results := make([][]Token, len(passes))
+	for i, state := range passes {
+		results[i] = appendTokens(nil, LexSync(input, state, opts...))
+	}
+	return results
+}
+
+// appendTokens appends all the tokens of the lexer to dst
+func appendTokens(dst []Token, l *Lexer) []Token {
+	for {
+		tok, ok := l.next()
+		if !ok {
+			return dst
+		}
+		dst = append(dst, tok)
+	}
+}
+
+// TokenArena is a storage for tokens which is reused across inputs,
+// reducing allocations when tokenizing many of them.
+// The zero value is an empty arena ready to use
+type TokenArena struct {
+	tokens []Token
+}
+
+// LexAll scans the whole input and returns all the emitted tokens
+// stored in the arena. The tokens stay valid until Reset only
+func (a *TokenArena) LexAll(input string, state StateFn, opts ...Option) []Token {
+	begin := len(a.tokens)
+	a.tokens = appendTokens(a.tokens, LexString(input, state, opts...))
+	return a.tokens[begin:len(a.tokens):len(a.tokens)]
+}
+
+// Len returns the number of tokens stored in the arena
+func (a *TokenArena) Len() int {
+	return len(a.tokens)
+}
+
+// Reset empties the arena keeping its storage for reuse.
+// Tokens returned by LexAll before Reset get overwritten by subsequent calls
+func (a *TokenArena) Reset() {
+	clear(a.tokens)
+	a.tokens = a.tokens[:0]
+}
@@ -0,0 +1,106 @@
+package lex_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestLexAll(t *testing.T) {
+	tokens := lex.LexAll("a bc", lexWords)
+	want := []lex.Token{
+		{Typ: tokWord, Pos: 0, Val: "a"},
+		{Typ: tokWord, Pos: 2, Val: "bc"},
+		{Typ: lex.TokEOF, Pos: 4},
+	}
+	if diff := lex.DiffTokens(tokens, want, lex.TokenCmpOptions{}); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestTokenArena(t *testing.T) {
+	var arena lex.TokenArena
+	first := arena.LexAll("a b", lexWords)
+	second := arena.LexAll("c", lexWords)
+	if len(first) != 3 || len(second) != 2 || arena.Len() != 5 {
+		t.Fatalf("got %v and %v, %d tokens in the arena", first, second, arena.Len())
+	}
+	if first[1].Val != "b" || second[0].Val != "c" {
+		t.Errorf("got %v and %v", first, second)
+	}
+	_ = append(second, lex.Token{Val: "x"})
+	if arena.LexAll("d", lexWords)[0].Val != "d" {
+		t.Error("appending to returned tokens overwrote the arena")
+	}
+
+	arena.Reset()
+	if arena.Len() != 0 {
+		t.Errorf("got %d tokens after Reset", arena.Len())
+	}
+	if tokens := arena.LexAll("e f", lexWords); len(tokens) != 3 || tokens[0].Val != "e" {
+		t.Errorf("got %v after Reset", tokens)
+	}
+}
+
+var files = func() []string {
+	files := make([]string, 100)
+	for i := range files {
+		files[i] = fmt.Sprintf("file %d with a few words in it", i)
+	}
+	return files
+}()
+
+func BenchmarkLexAll(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, f := range files {
+			lex.LexAll(f, lexWords)
+		}
+	}
+}
+
+func BenchmarkTokenArena(b *testing.B) {
+	b.ReportAllocs()
+	var arena lex.TokenArena
+	for i := 0; i < b.N; i++ {
+		arena.Reset()
+		for _, f := range files {
+			arena.LexAll(f, lexWords)
+		}
+	}
+}
+
+func TestMultiPass(t *testing.T) {
+	// lexComments skips everything but comments
+	var lexComments lex.StateFn
+	lexComments = func(l *lex.Lexer) lex.StateFn {
+		l.IgnoreRunes(func(r rune) bool { return r != '/' && r != lex.EOFRune })
+		if !l.AcceptUntil('\n') {
+			return lex.EOF
+		}
+		l.Emit(tokComment)
+		return lexComments
+	}
+	input := "a // one\nb c // two\n"
+	got := lex.MultiPass(input, []lex.StateFn{lexCode, lexComments})
+	want := [][]lex.Token{
+		lex.LexAll(input, lexCode),
+		{
+			{Typ: tokComment, Pos: 2, Val: "// one"},
+			{Typ: tokComment, Pos: 13, Val: "// two"},
+			{Typ: lex.TokEOF, Pos: 20},
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d passes, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if diff := lex.DiffTokens(got[i], want[i], lex.TokenCmpOptions{}); diff != "" {
+			t.Errorf("pass %d:\n%s", i, diff)
+		}
+	}
+	if n := len(got[0]); n != 6 {
+		t.Errorf("code pass got %d tokens, want 6", n)
+	}
+}
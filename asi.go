@@ -0,0 +1,75 @@
+package lex
+
+import "strings"
+
+// ASIInserter inserts semicolons into the tokens of a lexer the way Go does:
+// a synthetic semicolon follows the last token of a line, and of the input,
+// when endsStatement returns true for that token
+type ASIInserter struct {
+	l             *Lexer
+	input         string
+	semicolon     TokenType
+	endsStatement func(lastTok Token) bool
+
+	last    Token // last token returned
+	pending Token // token read from the lexer but preceded by a semicolon
+	held    bool  // whether pending is set
+}
+
+// NewASIInserter creates an inserter of tokens of type semicolon into the tokens
+// of the lexer scanning input. Positions of tokens must refer to input
+func NewASIInserter(l *Lexer, input string, semicolon TokenType, endsStatement func(lastTok Token) bool) *ASIInserter {
+	return &ASIInserter{
+		l:             l,
+		input:         input,
+		semicolon:     semicolon,
+		endsStatement: endsStatement,
+	}
+}
+
+// NextToken returns the next token from the lexer or a synthetic semicolon,
+// which has value "\n" and the position of the newline, or, when the input
+// does not end with a newline, empty value and the position of TokEOF
+func (a *ASIInserter) NextToken() Token {
+	tok := a.pending
+	if a.held {
+		a.held = false
+	} else {
+		tok = a.l.NextToken()
+	}
+	if semi, ok := a.insert(tok); ok {
+		a.pending, a.held = tok, true
+		tok = semi
+	}
+	a.last = tok
+	return tok
+}
+
+// insert returns the semicolon to insert before the token, if any
+func (a *ASIInserter) insert(tok Token) (Token, bool) {
+	if a.last == (Token{}) || a.last.Typ == a.semicolon || !a.endsStatement(a.last) {
+		return Token{}, false
+	}
+	if tok.Typ != TokEOF && tok.Typ != TokError && tok.Typ != 0 {
+		return a.newline(tok)
+	}
+	if tok.Typ != TokEOF {
+		return Token{}, false
+	}
+	if semi, ok := a.newline(tok); ok {
+		return semi, true
+	}
+	return Token{Typ: a.semicolon, Pos: tok.Pos}, true
+}
+
+// newline returns the semicolon at the first newline between the last token and tok
+func (a *ASIInserter) newline(tok Token) (Token, bool) {
+	end := a.last.Pos + Pos(len(a.last.Val))
+	if end > tok.Pos || int(tok.Pos) > len(a.input) {
+		return Token{}, false
+	}
+	if i := strings.IndexByte(a.input[end:tok.Pos], '\n'); i >= 0 {
+		return Token{Typ: a.semicolon, Pos: end + Pos(i), Val: "\n"}, true
+	}
+	return Token{}, false
+}
@@ -0,0 +1,82 @@
+package lex_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/redsift/lex"
+)
+
+var goPunctuation = lex.NewPunctuationTable(map[string]lex.TokenType{
+	"(": tokPunct, ")": tokPunct, "[": tokPunct, "]": tokPunct, "{": tokPunct, "}": tokPunct,
+	"+": tokPunct, "++": tokPunct, "-": tokPunct, "--": tokPunct,
+	"=": tokPunct, ":=": tokPunct, ",": tokPunct, ";": tokSemi,
+})
+
+var goKeywords = lex.NewKeywordTrie(map[string]lex.TokenType{
+	"if": tokIf, "for": tokFor, "func": tokFunc, "return": tokKeyword,
+})
+
+// lexGo emits identifiers, keywords and punctuation of a tiny subset of Go
+func lexGo(l *lex.Lexer) lex.StateFn {
+	l.IgnoreRunes(unicode.IsSpace)
+	if l.Peek() == lex.EOFRune {
+		return lex.EOF
+	}
+	if lex.ScanKeywordOrIdent(l, goKeywords, identCont, tokIdent) {
+		return lexGo
+	}
+	if _, ok := lex.ScanPunctuation(l, goPunctuation); !ok {
+		return l.Errorf("unexpected %q", l.Peek())
+	}
+	return lexGo
+}
+
+// endsGoStatement follows the rules of the Go specification
+func endsGoStatement(tok lex.Token) bool {
+	switch tok.Typ {
+	case tokIdent, tokKeyword:
+		return true
+	case tokPunct:
+		switch tok.Val {
+		case ")", "]", "}", "++", "--":
+			return true
+		}
+	}
+	return false
+}
+
+func TestASIInserter(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"x := 1\ny := x", "x := 1 ;\\n y := x ;"},
+		{"f(a,\nb)\n", "f ( a , b ) ;\\n"},
+		{"if x {\nreturn\n}\n", "if x { return ;\\n } ;\\n"},
+		{"func f() {\n\tx++\n\ty--\n}", "func f ( ) { x ++ ;\\n y -- ;\\n } ;"},
+		{"a[i]\n\n\nb", "a [ i ] ;\\n b ;"},
+		{"x = y +\nz", "x = y + z ;"},
+		{"x; y;\n", "x ; y ;"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		l := lex.LexString(tt.input, lexGo)
+		asi := lex.NewASIInserter(l, tt.input, tokSemi, endsGoStatement)
+		var got []string
+		for {
+			tok := asi.NextToken()
+			if tok.Typ == lex.TokEOF {
+				break
+			}
+			got = append(got, strings.ReplaceAll(tok.Val, "\n", ";\\n"))
+			if tok.Typ == tokSemi && tok.Val == "" {
+				got[len(got)-1] = ";"
+			}
+		}
+		if s := strings.Join(got, " "); s != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, s, tt.want)
+		}
+	}
+}
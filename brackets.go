@@ -0,0 +1,91 @@
+package lex
+
+import "fmt"
+
+var closing = map[rune]rune{'(': ')', '[': ']', '{': '}'}
+
+// BracketError reports an unbalanced bracket. Positions are mapped
+// like those of tokens, e.g. offset by BaseOffset
+type BracketError struct {
+	Pos      Pos  // Position of the unexpected close bracket, or of the end of the input
+	Open     Pos  // Position of the open bracket left unmatched, -1 if none is open
+	Expected rune // Close bracket matching the innermost open one, 0 if none is open
+	Got      rune // Close bracket found, 0 for an unclosed bracket
+}
+
+func (e *BracketError) Error() string {
+	switch {
+	case e.Expected == 0:
+		return fmt.Sprintf("unexpected %q", e.Got)
+	case e.Got == 0:
+		return fmt.Sprintf("unclosed %q, expected %q", opening(e.Expected), e.Expected)
+	}
+	return fmt.Sprintf("mismatched bracket: expected %q, got %q", e.Expected, e.Got)
+}
+
+// opening returns the open bracket for the close one
+func opening(close rune) rune {
+	for open, c := range closing {
+		if c == close {
+			return open
+		}
+	}
+	return 0
+}
+
+type openBracket struct {
+	close rune
+	pos   Pos
+}
+
+// BracketMatcher tracks the open brackets (, [ and { of a lexer
+// so its state functions can report unbalanced ones
+type BracketMatcher struct {
+	l     *Lexer
+	stack []openBracket
+}
+
+// NewBracketMatcher creates a matcher for the brackets scanned by l
+func NewBracketMatcher(l *Lexer) *BracketMatcher {
+	return &BracketMatcher{l: l}
+}
+
+// PushBracket records open, the bracket just consumed by the lexer
+func (m *BracketMatcher) PushBracket(open rune) {
+	c, ok := closing[open]
+	if !ok {
+		panic(fmt.Sprintf("lex: PushBracket called with %q, which is not an open bracket", open))
+	}
+	m.stack = append(m.stack, openBracket{c, m.l.tokenPos(m.l.pos - m.l.width)})
+}
+
+// PopBracket matches close, the bracket just consumed by the lexer, with
+// the innermost open bracket. It returns a *BracketError when they do not match
+// or no bracket is open; the open bracket stays open on a mismatch
+func (m *BracketMatcher) PopBracket(close rune) error {
+	pos := m.l.tokenPos(m.l.pos - m.l.width)
+	if len(m.stack) == 0 {
+		return &BracketError{Pos: pos, Open: -1, Got: close}
+	}
+	top := m.stack[len(m.stack)-1]
+	if top.close != close {
+		return &BracketError{Pos: pos, Open: top.pos, Expected: top.close, Got: close}
+	}
+	m.stack = m.stack[:len(m.stack)-1]
+	return nil
+}
+
+// Depth returns the number of open brackets
+func (m *BracketMatcher) Depth() int {
+	return len(m.stack)
+}
+
+// Unclosed returns a *BracketError for the innermost open bracket,
+// or nil when all brackets are closed. Call it at the end of the input
+func (m *BracketMatcher) Unclosed() error {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	top := m.stack[len(m.stack)-1]
+	return &BracketError{Pos: m.l.tokenPos(m.l.pos), Open: top.pos, Expected: top.close}
+}
@@ -0,0 +1,73 @@
+package lex_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+// checkBrackets scans input and returns the first bracket error
+func checkBrackets(input string, opts ...lex.Option) error {
+	var err error
+	collect(lex.LexString(input, func(l *lex.Lexer) lex.StateFn {
+		m := lex.NewBracketMatcher(l)
+		for {
+			switch r := l.Next(); r {
+			case '(', '[', '{':
+				m.PushBracket(r)
+			case ')', ']', '}':
+				if err = m.PopBracket(r); err != nil {
+					return l.Errorf("%v", err)
+				}
+			case lex.EOFRune:
+				if err = m.Unclosed(); err != nil {
+					return l.Errorf("%v", err)
+				}
+				return lex.EOF
+			}
+		}
+	}, opts...))
+	return err
+}
+
+func TestBracketMatcher(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *lex.BracketError
+		msg   string
+	}{
+		{"f(a[1], {b: (c)})", nil, ""},
+		{"", nil, ""},
+		{"([)]", &lex.BracketError{Pos: 2, Open: 1, Expected: ']', Got: ')'}, `mismatched bracket: expected ']', got ')'`},
+		{"a)", &lex.BracketError{Pos: 1, Open: -1, Got: ')'}, `unexpected ')'`},
+		{"{x: [1, 2]", &lex.BracketError{Pos: 10, Open: 0, Expected: '}'}, `unclosed '{', expected '}'`},
+	}
+	for _, tt := range tests {
+		err := checkBrackets(tt.input)
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", tt.input, err)
+			}
+			continue
+		}
+		var got *lex.BracketError
+		if !errors.As(err, &got) {
+			t.Errorf("%q: got %v, want %v", tt.input, err, tt.want)
+			continue
+		}
+		if *got != *tt.want || got.Error() != tt.msg {
+			t.Errorf("%q: got %+v %q, want %+v %q", tt.input, *got, got, *tt.want, tt.msg)
+		}
+	}
+}
+
+func TestBracketMatcherBaseOffset(t *testing.T) {
+	var got *lex.BracketError
+	if err := checkBrackets("([)]", lex.BaseOffset(1000)); !errors.As(err, &got) || got.Pos != 1002 || got.Open != 1001 {
+		t.Errorf("got %+v, want positions offset by 1000", err)
+	}
+	if err := checkBrackets("(x", lex.BaseOffset(1000)); !errors.As(err, &got) || got.Pos != 1002 || got.Open != 1000 {
+		t.Errorf("got %+v, want positions offset by 1000", err)
+	}
+}
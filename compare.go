@@ -0,0 +1,63 @@
+package lex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenCmpOptions configures comparison of tokens
+type TokenCmpOptions struct {
+	IgnorePos bool // Do not compare positions
+	IgnoreVal bool // Do not compare values
+
+	TypeName func(TokenType) string // Names types in DiffTokens output, see TypeNames
+}
+
+// equal compares all the fields of the tokens but the ignored ones
+func (o TokenCmpOptions) equal(a, b Token) bool {
+	if o.IgnorePos {
+		a.Pos, b.Pos = 0, 0
+	}
+	if o.IgnoreVal {
+		a.Val, b.Val = "", ""
+	}
+	ai, bi := a.Info, b.Info
+	a.Info, b.Info = nil, nil
+	return a == b && (ai == bi || ai != nil && bi != nil && *ai == *bi)
+}
+
+// TokensEqual reports whether the token streams are equal
+func TokensEqual(a, b []Token, cmp TokenCmpOptions) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !cmp.equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffTokens describes differences between the token streams in the format
+// of DumpTokens, with types named by cmp.TypeName, a pair of lines per differing index:
+//
+//	-2: ident 5 "x"
+//	+2: ident 6 "x"
+//
+// Tokens missing from a shorter stream have no line. It returns "" for equal streams
+func DiffTokens(a, b []Token, cmp TokenCmpOptions) string {
+	var d strings.Builder
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i < len(a) && i < len(b) && cmp.equal(a[i], b[i]) {
+			continue
+		}
+		if i < len(a) {
+			fmt.Fprintf(&d, "-%d: %s\n", i, formatToken(a[i], cmp.TypeName))
+		}
+		if i < len(b) {
+			fmt.Fprintf(&d, "+%d: %s\n", i, formatToken(b[i], cmp.TypeName))
+		}
+	}
+	return d.String()
+}
@@ -0,0 +1,38 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestTokensEqual(t *testing.T) {
+	base := lex.LexAll("a b", lexWords)
+	tests := []struct {
+		name  string
+		other []lex.Token
+		cmp   lex.TokenCmpOptions
+		equal bool
+		diff  string
+	}{
+		{"equal", lex.LexAll("a b", lexWords), lex.TokenCmpOptions{}, true, ""},
+		{"value", lex.LexAll("a c", lexWords), lex.TokenCmpOptions{}, false,
+			"-1: Token(4) 2 \"b\"\n+1: Token(4) 2 \"c\"\n"},
+		{"value ignored", lex.LexAll("a c", lexWords), lex.TokenCmpOptions{IgnoreVal: true}, true, ""},
+		{"position", lex.LexAll("a  b", lexWords), lex.TokenCmpOptions{}, false,
+			"-1: Token(4) 2 \"b\"\n+1: Token(4) 3 \"b\"\n-2: EOF 3 \"\"\n+2: EOF 4 \"\"\n"},
+		{"position ignored", lex.LexAll("a  b", lexWords), lex.TokenCmpOptions{IgnorePos: true}, true, ""},
+		{"longer", lex.LexAll("a b c", lexWords), lex.TokenCmpOptions{IgnorePos: true}, false,
+			"-2: EOF 3 \"\"\n+2: Token(4) 4 \"c\"\n+3: EOF 5 \"\"\n"},
+		{"named", lex.LexAll("a c", lexWords), lex.TokenCmpOptions{TypeName: typeName}, false,
+			"-1: word 2 \"b\"\n+1: word 2 \"c\"\n"},
+	}
+	for _, tt := range tests {
+		if got := lex.TokensEqual(base, tt.other, tt.cmp); got != tt.equal {
+			t.Errorf("%s: TokensEqual() = %v, want %v", tt.name, got, tt.equal)
+		}
+		if got := lex.DiffTokens(base, tt.other, tt.cmp); got != tt.diff {
+			t.Errorf("%s: DiffTokens() =\n%s\nwant\n%s", tt.name, got, tt.diff)
+		}
+	}
+}
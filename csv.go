@@ -0,0 +1,35 @@
+package lex
+
+// ScanCSVField consumes a field of a delimiter-separated record: either
+// a literal in double quotes, where a doubled quote stands for the quote itself,
+// or a run of runes up to the delimiter or the end of the line.
+// It returns false for an unterminated quoted field
+func ScanCSVField(l *Lexer, delim rune) bool {
+	if l.Peek() == '"' {
+		_, ok := ScanDoubledQuote(l, '"')
+		return ok
+	}
+	l.AcceptUntil(delim, '\n', '\r')
+	return true
+}
+
+// ScanRecord emits a token of fieldType per field and of sepType per delimiter
+// of the record up to the end of the line, which is not consumed, or of the input.
+// Empty fields are emitted as empty tokens; delimiters are ignored when sepType is zero.
+// It returns false for an unterminated quoted field
+func ScanRecord(l *Lexer, delim rune, fieldType, sepType TokenType) bool {
+	for {
+		if !ScanCSVField(l, delim) {
+			return false
+		}
+		l.Emit(fieldType)
+		if !l.Accept(delim) {
+			return true
+		}
+		if sepType != 0 {
+			l.Emit(sepType)
+		} else {
+			l.Ignore()
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestScanRecord(t *testing.T) {
+	const sep = tokSpace
+	tests := []struct {
+		name    string
+		input   string
+		sepType lex.TokenType
+		ok      bool
+		want    []string
+	}{
+		{"plain", "a,b,c\nd", sep, true, []string{"a", ",", "b", ",", "c"}},
+		{"fields only", "a,b,c", 0, true, []string{"a", "b", "c"}},
+		{"empty fields", ",a,,b", 0, true, []string{"", "a", "", "b"}},
+		{"quoted", `"x,y",z,"say ""hi"""`, 0, true, []string{`"x,y"`, "z", `"say ""hi"""`}},
+		{"trailing delimiter", "a,b,\r\n", sep, true, []string{"a", ",", "b", ",", ""}},
+		{"empty record", "", 0, true, []string{""}},
+		{"unterminated", `a,"b`, 0, false, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ok bool
+			tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+				ok = lex.ScanRecord(l, ',', tokWord, tt.sepType)
+				return lex.EOF
+			}))
+			if ok != tt.ok {
+				t.Errorf("ScanRecord() = %v, want %v", ok, tt.ok)
+			}
+			var got []string
+			for _, tok := range tokens {
+				if tok.Typ == tokWord || tok.Typ == sep {
+					got = append(got, tok.Val)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("token %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
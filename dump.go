@@ -0,0 +1,59 @@
+package lex
+
+import (
+	"fmt"
+	"io"
+)
+
+// DumpTokens scans the input and writes a line per emitted token
+// with its type, position and quoted value, e.g.
+//
+//	keyword 0 "if"
+//	EOF 2 ""
+//
+// Types are named by the TypeNames option, falling back to TokenType.String.
+// The output is stable across runs and is suitable for golden files
+func DumpTokens(w io.Writer, input string, state StateFn, opts ...Option) error {
+	l := LexSync(input, state, opts...)
+	for {
+		tok, ok := l.next()
+		if !ok {
+			return nil
+		}
+		if _, err := fmt.Fprintln(w, formatToken(tok, l.typeName)); err != nil {
+			return err
+		}
+	}
+}
+
+// TypeNames names token types in the output of DumpTokens, so golden files
+// do not change when a grammar renumbers its types. Types that name maps to ""
+// are described by TokenType.String
+func TypeNames(name func(TokenType) string) Option {
+	return func(l *Lexer) {
+		l.typeName = name
+	}
+}
+
+// formatToken describes the token with its type named by name, if not nil,
+// its position and its quoted value
+func formatToken(tok Token, name func(TokenType) string) string {
+	typ := ""
+	if name != nil {
+		typ = name(tok.Typ)
+	}
+	if typ == "" {
+		typ = tok.Typ.String()
+	}
+	return fmt.Sprintf("%s %d %q", typ, tok.Pos, tok.Val)
+}
+
+// ObservedTokenTypes scans the input and returns the number of emitted tokens
+// per type, which helps to find token types a grammar never emits
+func ObservedTokenTypes(input string, state StateFn, opts ...Option) map[TokenType]int {
+	types := make(map[TokenType]int)
+	for _, tok := range LexAll(input, state, opts...) {
+		types[tok.Typ]++
+	}
+	return types
+}
@@ -0,0 +1,88 @@
+package lex_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/redsift/lex"
+)
+
+const dumpGolden = `Token(4) 0 "say"
+Token(4) 4 "\"hi\""
+Token(4) 9 "日本"
+EOF 15 ""
+`
+
+func TestDumpTokens(t *testing.T) {
+	var b strings.Builder
+	if err := lex.DumpTokens(&b, `say "hi" 日本`, lexWords); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != dumpGolden {
+		t.Errorf("got\n%s\nwant\n%s", got, dumpGolden)
+	}
+
+	b.Reset()
+	if err := lex.DumpTokens(&b, "a \u00ffb", lexWords, lex.MaxRune(unicode.MaxASCII)); err != nil {
+		t.Fatal(err)
+	}
+	const want = `Token(4) 0 "a"
+Error 2 "rune U+00FF is out of the allowed range"
+`
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// typeName names some of the token types of the tests, leaving the others,
+// including TokEOF, to TokenType.String
+func typeName(t lex.TokenType) string {
+	switch t {
+	case tokWord:
+		return "word"
+	case tokComment:
+		return "comment"
+	}
+	return ""
+}
+
+func TestDumpTokensTypeNames(t *testing.T) {
+	var b strings.Builder
+	if err := lex.DumpTokens(&b, "/x\na", lexCode, lex.TypeNames(typeName)); err != nil {
+		t.Fatal(err)
+	}
+	const want = `comment 0 "/x"
+word 3 "a"
+EOF 4 ""
+`
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestDumpTokensWriteError(t *testing.T) {
+	if err := lex.DumpTokens(failingWriter{}, "a b", lexWords); err == nil || err.Error() != "disk full" {
+		t.Errorf("got error %v, want disk full", err)
+	}
+}
+
+func TestObservedTokenTypes(t *testing.T) {
+	got := lex.ObservedTokenTypes("/x\na b\n/y\nc", lexCode)
+	want := map[lex.TokenType]int{tokWord: 3, tokComment: 2, lex.TokEOF: 1}
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for typ, n := range want {
+		if got[typ] != n {
+			t.Errorf("got %d tokens of type %v, want %d", got[typ], typ, n)
+		}
+	}
+}
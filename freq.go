@@ -0,0 +1,72 @@
+package lex
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FrequencyRecorder counts how often each token value is emitted regardless
+// of its type, e.g. to order keyword checks by frequency over a corpus.
+// It is safe for concurrent use by multiple lexers.
+// The zero value is an empty recorder ready to use
+type FrequencyRecorder struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// RecordFrequencies makes the lexer count values of the emitted tokens,
+// except TokEOF and TokError, in r
+func RecordFrequencies(r *FrequencyRecorder) Option {
+	return func(l *Lexer) {
+		l.frequencies = r
+	}
+}
+
+// record counts an occurrence of val
+func (r *FrequencyRecorder) record(val string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counts == nil {
+		r.counts = make(map[string]int)
+	}
+	if _, ok := r.counts[val]; !ok {
+		val = strings.Clone(val) // do not retain the input
+	}
+	r.counts[val]++
+}
+
+// Count returns the number of occurrences of val
+func (r *FrequencyRecorder) Count(val string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[val]
+}
+
+// Frequencies returns a copy of the recorded counts by value
+func (r *FrequencyRecorder) Frequencies() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[string]int, len(r.counts))
+	for val, n := range r.counts {
+		counts[val] = n
+	}
+	return counts
+}
+
+// MostFrequent returns the recorded values from the most to the least frequent,
+// equally frequent ones in lexical order
+func (r *FrequencyRecorder) MostFrequent() []string {
+	counts := r.Frequencies()
+	vals := make([]string, 0, len(counts))
+	for val := range counts {
+		vals = append(vals, val)
+	}
+	sort.Slice(vals, func(i, j int) bool {
+		if counts[vals[i]] != counts[vals[j]] {
+			return counts[vals[i]] > counts[vals[j]]
+		}
+		return vals[i] < vals[j]
+	})
+	return vals
+}
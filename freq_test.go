@@ -0,0 +1,38 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestFrequencyRecorder(t *testing.T) {
+	var r lex.FrequencyRecorder
+	for _, input := range []string{"if x if y", "for x if"} {
+		collect(lex.LexString(input, lexWords, lex.RecordFrequencies(&r)))
+	}
+	want := map[string]int{"if": 3, "x": 2, "y": 1, "for": 1}
+	got := r.Frequencies()
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for val, n := range want {
+		if got[val] != n || r.Count(val) != n {
+			t.Errorf("count of %q = %d, want %d", val, got[val], n)
+		}
+	}
+	if r.Count("") != 0 {
+		t.Errorf("EOF tokens were counted")
+	}
+	order := r.MostFrequent()
+	wantOrder := []string{"if", "x", "for", "y"}
+	if len(order) != len(wantOrder) {
+		t.Fatalf("MostFrequent() = %v, want %v", order, wantOrder)
+	}
+	for i := range wantOrder {
+		if order[i] != wantOrder[i] {
+			t.Errorf("MostFrequent() = %v, want %v", order, wantOrder)
+			break
+		}
+	}
+}
@@ -1 +1,3 @@
 module github.com/redsift/lex
+
+go 1.23
@@ -0,0 +1,44 @@
+package lex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StateGraph records possible transitions between named states.
+// As transitions are chosen by state functions at run time, each state
+// has to register the successors it can return. The zero value is ready to use
+type StateGraph struct {
+	edges []transition
+	known map[transition]bool
+}
+
+type transition struct {
+	from, to string
+}
+
+// RegisterTransition records that the state from can return the state to.
+// Repeated registrations are ignored
+func (g *StateGraph) RegisterTransition(from, to string) {
+	t := transition{from, to}
+	if g.known[t] {
+		return
+	}
+	if g.known == nil {
+		g.known = make(map[transition]bool)
+	}
+	g.known[t] = true
+	g.edges = append(g.edges, t)
+}
+
+// ExportDOT describes the graph in Graphviz DOT language.
+// Transitions are listed in the order of registration
+func (g *StateGraph) ExportDOT() string {
+	var b strings.Builder
+	b.WriteString("digraph states {\n")
+	for _, t := range g.edges {
+		fmt.Fprintf(&b, "\t%q -> %q;\n", t.from, t.to)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,27 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestStateGraphExportDOT(t *testing.T) {
+	var g lex.StateGraph
+	g.RegisterTransition("text", "action")
+	g.RegisterTransition("action", "text")
+	g.RegisterTransition("text", "text")
+	g.RegisterTransition("action", "text")
+	g.RegisterTransition("text", "EOF")
+
+	const want = `digraph states {
+	"text" -> "action";
+	"action" -> "text";
+	"text" -> "text";
+	"text" -> "EOF";
+}
+`
+	if got := g.ExportDOT(); got != want {
+		t.Errorf("ExportDOT() =\n%s\nwant\n%s", got, want)
+	}
+}
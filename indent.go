@@ -0,0 +1,38 @@
+package lex
+
+// Indentation describes the composition of leading white space
+type Indentation struct {
+	Tabs   int // Number of tabs
+	Spaces int // Number of spaces
+}
+
+// Mixed reports whether the indentation has both tabs and spaces
+func (i Indentation) Mixed() bool {
+	return i.Tabs > 0 && i.Spaces > 0
+}
+
+// MeasureIndent counts tabs and spaces at the beginning of s
+func MeasureIndent(s string) Indentation {
+	var i Indentation
+	for _, r := range s {
+		switch r {
+		case '\t':
+			i.Tabs++
+		case ' ':
+			i.Spaces++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// EmitIndent consumes a run of tabs and spaces and emits it as a token of type t
+// with its Indentation in Info. It returns false when there is no such run
+func (l *Lexer) EmitIndent(t TokenType) bool {
+	if !l.AcceptRun(' ', '\t') {
+		return false
+	}
+	l.emit(Token{Typ: t, Info: &TokenInfo{Indent: MeasureIndent(l.Current())}})
+	return true
+}
@@ -0,0 +1,39 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestEmitIndent(t *testing.T) {
+	tests := []struct {
+		input string
+		ok    bool
+		val   string
+		want  lex.Indentation
+		mixed bool
+	}{
+		{"\t\tx", true, "\t\t", lex.Indentation{Tabs: 2}, false},
+		{"    x", true, "    ", lex.Indentation{Spaces: 4}, false},
+		{"\t  \tx", true, "\t  \t", lex.Indentation{Tabs: 2, Spaces: 2}, true},
+		{"x", false, "", lex.Indentation{}, false},
+	}
+	for _, tt := range tests {
+		var ok bool
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			ok = l.EmitIndent(tokSpace)
+			return lex.EOF
+		}))
+		if ok != tt.ok {
+			t.Errorf("%q: EmitIndent() = %v, want %v", tt.input, ok, tt.ok)
+		}
+		if !tt.ok {
+			continue
+		}
+		indent := tokens[0].Indent()
+		if tokens[0].Val != tt.val || indent != tt.want || indent.Mixed() != tt.mixed {
+			t.Errorf("%q: got %q with %+v, want %q with %+v", tt.input, tokens[0].Val, tokens[0].Indent(), tt.val, tt.want)
+		}
+	}
+}
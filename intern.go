@@ -0,0 +1,78 @@
+package lex
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// Interner deduplicates token values
+type Interner interface {
+	// Intern returns a value equal to s, the same for equal values where possible
+	Intern(s string) string
+}
+
+// Intern makes the lexer pass values of emitted tokens through the interner.
+// The interner is responsible for copying values, so CopyValues has no effect
+func Intern(interner Interner) Option {
+	return func(l *Lexer) {
+		l.interner = interner
+	}
+}
+
+// LRUInterner is an Interner keeping at most a fixed number of recently used values,
+// so its memory stays bounded for never-ending streams of distinct values.
+// It is safe for concurrent use by multiple lexers
+type LRUInterner struct {
+	mu       sync.Mutex
+	capacity int
+	values   map[string]*list.Element
+	recent   *list.List // values from most to least recently used
+	hits     uint64
+	misses   uint64
+}
+
+// NewLRUInterner creates an interner keeping up to capacity values
+func NewLRUInterner(capacity int) *LRUInterner {
+	return &LRUInterner{
+		capacity: capacity,
+		values:   make(map[string]*list.Element, capacity),
+		recent:   list.New(),
+	}
+}
+
+// Intern returns the kept value equal to s, or keeps a copy of s evicting
+// the least recently used value when the interner is full
+func (i *LRUInterner) Intern(s string) string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if e, ok := i.values[s]; ok {
+		i.hits++
+		i.recent.MoveToFront(e)
+		return e.Value.(string)
+	}
+	i.misses++
+	s = strings.Clone(s)
+	if i.capacity <= 0 {
+		return s
+	}
+	if i.recent.Len() >= i.capacity {
+		delete(i.values, i.recent.Remove(i.recent.Back()).(string))
+	}
+	i.values[s] = i.recent.PushFront(s)
+	return s
+}
+
+// Len returns the number of kept values
+func (i *LRUInterner) Len() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.recent.Len()
+}
+
+// Stats returns the number of Intern calls which found the value kept and which did not
+func (i *LRUInterner) Stats() (hits, misses uint64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.hits, i.misses
+}
@@ -0,0 +1,53 @@
+package lex_test
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/redsift/lex"
+)
+
+func same(a, b string) bool {
+	return unsafe.StringData(a) == unsafe.StringData(b)
+}
+
+func TestLRUInterner(t *testing.T) {
+	i := lex.NewLRUInterner(2)
+	hot := i.Intern(strings.Clone("hot"))
+	i.Intern("a")
+	for _, s := range []string{"b", "c", "d"} {
+		if !same(i.Intern(strings.Clone("hot")), hot) {
+			t.Fatalf("hot value evicted before %q", s)
+		}
+		i.Intern(s)
+	}
+	if i.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", i.Len())
+	}
+	if hits, misses := i.Stats(); hits != 3 || misses != 5 {
+		t.Errorf("Stats() = (%d, %d), want (3, 5)", hits, misses)
+	}
+	// "a" to "c" got evicted, so interning them again misses
+	for _, s := range []string{"a", "b", "c"} {
+		i.Intern(s)
+	}
+	if hits, misses := i.Stats(); hits != 3 || misses != 8 {
+		t.Errorf("Stats() after eviction = (%d, %d), want (3, 8)", hits, misses)
+	}
+}
+
+func TestIntern(t *testing.T) {
+	input := "x y x y z x"
+	i := lex.NewLRUInterner(10)
+	tokens := collect(lex.LexString(input, lexWords, lex.Intern(i)))
+	if !same(tokens[0].Val, tokens[2].Val) || !same(tokens[0].Val, tokens[5].Val) {
+		t.Error("equal values are not interned")
+	}
+	if sharesMemory(tokens[0].Val, input) {
+		t.Error("interned value shares memory with the input")
+	}
+	if hits, misses := i.Stats(); hits != 3 || misses != 4 {
+		t.Errorf("Stats() = (%d, %d), want (3, 4)", hits, misses)
+	}
+}
@@ -0,0 +1,36 @@
+package lex
+
+import "iter"
+
+// Error is the error reported for a TokError token
+type Error struct {
+	Pos Pos    // The starting position, in bytes, of the error token
+	Msg string // Text of the error
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// All2 returns an iterator over the tokens yielding (token, nil) for regular tokens,
+// including TokEOF, and (Token{}, *Error) for the error terminating the scan.
+// Breaking out of the loop drains the lexer.
+// Called by the parser, not in the lexing goroutine
+func (l *Lexer) All2() iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		defer l.Drain()
+		for {
+			tok, ok := l.next()
+			if !ok {
+				return
+			}
+			if tok.Typ == TokError {
+				yield(Token{}, &Error{tok.Pos, tok.Val})
+				return
+			}
+			if !yield(tok, nil) {
+				return
+			}
+		}
+	}
+}
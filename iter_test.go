@@ -0,0 +1,68 @@
+package lex_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestAll2(t *testing.T) {
+	var vals []string
+	for tok, err := range lex.LexString("a b c", lexWords).All2() {
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		vals = append(vals, tok.String())
+	}
+	if got, want := len(vals), 4; got != want {
+		t.Fatalf("got %d tokens %q, want %d", got, vals, want)
+	}
+	if vals[3] != "EOF" {
+		t.Errorf("last token = %s, want EOF", vals[3])
+	}
+}
+
+func TestAll2Error(t *testing.T) {
+	var (
+		n   int
+		err error
+	)
+	for tok, e := range lex.LexString("a b c", lexWords, lex.MaxInputBytes(4)).All2() {
+		if e != nil {
+			if tok != (lex.Token{}) {
+				t.Errorf("error yielded with token %#v", tok)
+			}
+			err = e
+			continue
+		}
+		n++
+	}
+	var lexErr *lex.Error
+	if !errors.As(err, &lexErr) {
+		t.Fatalf("got error %v, want *lex.Error", err)
+	}
+	if lexErr.Pos != 4 || lexErr.Msg != "input too large" {
+		t.Errorf("got error %q at %d", lexErr.Msg, lexErr.Pos)
+	}
+	if n != 2 {
+		t.Errorf("got %d tokens before the error, want 2", n)
+	}
+}
+
+func TestAll2Break(t *testing.T) {
+	done := make(chan struct{})
+	l := lex.LexString("a b c d", func(l *lex.Lexer) lex.StateFn {
+		defer close(done)
+		for l.Next() != lex.EOFRune {
+			l.Emit(tokWord)
+		}
+		return lex.EOF
+	})
+	for tok := range l.All2() {
+		if tok.Val == "b" {
+			break
+		}
+	}
+	<-done // the lexing goroutine finishes only if drained
+}
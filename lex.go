@@ -2,6 +2,9 @@ package lex
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -19,8 +22,22 @@ const (
 	FirstCustomToken
 )
 
+func (t TokenType) String() string {
+	switch t {
+	case TokEOF:
+		return "EOF"
+	case TokError:
+		return "Error"
+	}
+	return "Token(" + strconv.Itoa(int(t)) + ")"
+}
+
+// EOFRune is returned by Next and Peek at the end of the input
+const EOFRune rune = -1
+
 const (
-	eof = -1
+	eof = EOFRune
+	bom = "\uFEFF"
 )
 
 // Pos represents token position in the input
@@ -28,9 +45,49 @@ type Pos int
 
 // Token represents a token returned from the scanner.
 type Token struct {
-	Typ TokenType // Type
-	Pos Pos       // The starting position, in bytes, of this Token in the input string
-	Val string    // Value
+	Typ  TokenType  // Type
+	Pos  Pos        // The starting position, in bytes, of this Token in the input string
+	Val  string     // Value
+	Hash uint64     // Hash of the value, set with WithValueHash option only
+	Info *TokenInfo // Details set by some helpers, nil for most tokens
+}
+
+// TokenInfo holds details of a token set by the helper emitting it,
+// apart from Token so that plain tokens stay small
+type TokenInfo struct {
+	Quote        QuoteStyle  // Quoting of a string literal, set by ScanString
+	Unterminated bool        // Construct is cut short by the end of the input, see EmitUnterminated
+	Cooked       string      // Normalized value of a number, set by ScanNumber
+	Indent       Indentation // Composition of leading white space, set by EmitIndent
+}
+
+// Quote returns the quoting of a string literal emitted by ScanString
+func (i Token) Quote() QuoteStyle {
+	if i.Info == nil {
+		return QuoteNone
+	}
+	return i.Info.Quote
+}
+
+// Unterminated reports whether the construct is cut short by the end of the input
+func (i Token) Unterminated() bool {
+	return i.Info != nil && i.Info.Unterminated
+}
+
+// Cooked returns the normalized value of a number emitted by ScanNumber
+func (i Token) Cooked() string {
+	if i.Info == nil {
+		return ""
+	}
+	return i.Info.Cooked
+}
+
+// Indent returns the composition of white space emitted by EmitIndent
+func (i Token) Indent() Indentation {
+	if i.Info == nil {
+		return Indentation{}
+	}
+	return i.Info.Indent
 }
 
 func (i Token) String() string {
@@ -48,6 +105,19 @@ func (i Token) String() string {
 	return fmt.Sprintf("%q", i.Val)
 }
 
+// RuneOffset returns the position of the token as an index in runes of input,
+// the string the token was lexed from. Positions beyond input are clamped to its end
+func (i Token) RuneOffset(input string) int {
+	pos := int(i.Pos)
+	if pos < 0 {
+		return 0
+	}
+	if pos > len(input) {
+		pos = len(input)
+	}
+	return utf8.RuneCountInString(input[:pos])
+}
+
 // StateFn represents the state of the scanner
 // as a function that returns the Next state
 type StateFn func(*Lexer) StateFn
@@ -58,37 +128,189 @@ type Lexer struct {
 	start  Pos        // start position of this Token
 	pos    Pos        // current position in the input
 	width  Pos        // width of last rune read from input
-	tokens chan Token // channel of scanned tokens
+	tokens chan Token // channel of scanned tokens, nil for synchronous lexers
+	state  StateFn    // state to execute next
+	queue  []Token    // tokens emitted by a synchronous lexer but not delivered yet
+	head   int        // index of the next token to deliver in queue
+
+	normalize func(string) string // normalizer applied to the input before scanning
+	normMap   []posMapping        // maps positions in normalized input back to the original
+	tabWidth  int                 // width of tab stops to expand tabs to, 0 to keep tabs
+	tabMap    []posMapping        // maps positions in expanded input back to the original
+
+	copyValues    bool // copy token values instead of slicing the input
+	maxInputBytes Pos  // limit of the input to scan, 0 means unlimited
+	baseOffset    Pos  // added to positions of emitted tokens
+	maxRune       rune // runes above are reported as errors
+	skipBOM       bool // skip the byte order mark at the beginning of the input
+	rejectBOM     bool // report byte order marks past the beginning of the input
+
+	runeWidth func(rune) int         // display width of runes, RuneDisplayWidth if nil
+	valueHash func(string) uint64    // hash of token values, nil when disabled
+	interner  Interner               // deduplicates token values, nil when disabled
+	typeName  func(TokenType) string // names token types for DumpTokens, nil for TokenType.String
+
+	frequencies *FrequencyRecorder // counts token values, nil when disabled
+
+	unterminated UnterminatedPolicy // how EmitUnterminated reports constructs
+	yieldEvery   int                // number of tokens between calls of yield
+	yield        func()             // called every yieldEvery tokens
+	emitted      int                // number of tokens delivered
+	bof          TokenType          // type of the token emitted before the first state, 0 for none
+	preciseUntil Pos                // position to switch to the coarse state at, never when coarse is nil
+	coarse       StateFn            // state replacing the scanner past preciseUntil, nil once switched
+	runeLog      *RuneLog           // records the runes returned by Next, nil when disabled
+	expect       string             // hint appended to the next error, see Expect
+
+	progress      func(pos Pos, line int) // progress callback, nil when disabled
+	progressEvery Pos                     // number of bytes between calls of progress
+	nextProgress  Pos                     // position to call progress at
+	progressPos   Pos                     // position of the last call of progress
+	progressLine  int                     // line number at progressPos
+
+	maxSteps int // limit of runes read per emitted token, 0 means unlimited
+	steps    int // number of runes read since the last emitted or ignored token
+
+	coalesce map[TokenType]bool // types of adjacent tokens to merge
+	held     Token              // token held back to merge the next one into
+	holding  bool               // whether held is set
+
+	halted       bool // scan was stopped by an error detected while reading input
+	maxLookahead Pos  // longest pending input observed
 }
 
+// Option configures a Lexer created by LexString or LexSync
+type Option func(*Lexer)
+
 // LexString creates a new *Lexer that will scan given input starting from the state
-func LexString(input string, state StateFn) *Lexer {
+func LexString(input string, state StateFn, opts ...Option) *Lexer {
+	l := newLexer(input, state, opts)
+	l.tokens = make(chan Token)
+	go l.run()
+	return l
+}
+
+// newLexer creates a lexer configured by the options
+func newLexer(input string, state StateFn, opts []Option) *Lexer {
 	l := &Lexer{
-		input:  input,
-		tokens: make(chan Token),
+		input:   input,
+		state:   state,
+		maxRune: unicode.MaxRune,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.tabWidth > 0 {
+		l.input, l.tabMap = expandTabs(l.input, l.tabWidth)
+	}
+	if l.normalize != nil {
+		l.input, l.normMap = normalizeInput(l.input, l.normalize)
+	}
+	if l.skipBOM && strings.HasPrefix(l.input, bom) {
+		l.start = Pos(len(bom))
+		l.pos = l.start
+		if l.runeLog != nil {
+			*l.runeLog = append(*l.runeLog, RuneRecord{0, '\uFEFF', bom})
+		}
 	}
-	go l.run(state)
 	return l
 }
 
 // run scans the input by executing state functions until
 // the state is nil
-func (l *Lexer) run(start StateFn) {
-	for state := start; state != nil; {
-		state = state(l)
+func (l *Lexer) run() {
+	for l.state != nil {
+		l.step()
 	}
 	close(l.tokens) // No more tokens will be delivered
 }
 
+// budgetExceeded aborts a state function exceeding MaxStepsPerToken
+type budgetExceeded struct{}
+
+// step executes the current state function
+func (l *Lexer) step() {
+	if l.maxSteps > 0 {
+		defer l.recoverBudget()
+	}
+	if l.bof != 0 {
+		l.send(Token{Typ: l.bof})
+		l.bof = 0
+	}
+	l.state = l.state(l)
+	if l.halted {
+		l.state = nil
+	}
+	if l.coarse != nil && l.state != nil && l.start >= l.preciseUntil {
+		l.state, l.coarse = l.coarse, nil
+	}
+	if l.state == nil {
+		l.flush()
+	}
+}
+
+// recoverBudget ends the scan aborted by exceeding MaxStepsPerToken
+func (l *Lexer) recoverBudget() {
+	if r := recover(); r != nil {
+		if _, ok := r.(budgetExceeded); !ok {
+			panic(r)
+		}
+		l.state = nil
+		l.flush()
+	}
+}
+
 // Next returns the next rune in the input
 func (l *Lexer) Next() rune {
-	if int(l.pos) >= len(l.input) {
+	if l.maxSteps > 0 {
+		if l.steps++; l.steps > l.maxSteps {
+			l.halt(l.start, "token scan budget exceeded")
+			panic(budgetExceeded{}) // abort the state function, recovered by step
+		}
+	}
+	pos := l.pos
+	r := l.read()
+	if l.runeLog != nil {
+		*l.runeLog = append(*l.runeLog, RuneRecord{pos, r, l.input[pos:l.pos]})
+	}
+	if l.progress != nil && l.pos >= l.nextProgress {
+		l.reportProgress()
+	}
+	return r
+}
+
+// reportProgress calls the progress callback, counting lines read since the last call
+func (l *Lexer) reportProgress() {
+	l.progressLine += strings.Count(l.input[l.progressPos:l.pos], "\n")
+	l.progressPos = l.pos
+	l.nextProgress = l.pos + l.progressEvery
+	l.progress(l.tokenPos(l.pos), l.progressLine)
+}
+
+// read consumes the next rune in the input
+func (l *Lexer) read() rune {
+	if l.halted || int(l.pos) >= len(l.input) {
 		l.width = 0
 		return eof
 	}
 	r, w := utf8.DecodeRuneInString(l.input[l.pos:])
+	if l.maxInputBytes > 0 && l.sourcePos(l.pos+Pos(w)) > l.maxInputBytes {
+		l.halt(l.pos, "input too large")
+		return eof
+	}
+	if l.rejectBOM && r == '\uFEFF' && l.pos > 0 {
+		l.halt(l.pos, "unexpected BOM")
+		return eof
+	}
+	if r > l.maxRune {
+		l.halt(l.pos, fmt.Sprintf("rune %U is out of the allowed range", r))
+		return eof
+	}
 	l.width = Pos(w)
 	l.pos += l.width
+	if n := l.pos - l.start; n > l.maxLookahead {
+		l.maxLookahead = n
+	}
 	return r
 }
 
@@ -99,33 +321,218 @@ func (l *Lexer) Peek() rune {
 	return r
 }
 
+// RuneClass is a broad category of runes state functions usually branch on
+type RuneClass int
+
+const (
+	// ClassOther is a rune of none of the other classes, e.g. a control character
+	ClassOther RuneClass = iota
+	// ClassEOF is the end of the input
+	ClassEOF
+	// ClassLetter is a Unicode letter
+	ClassLetter
+	// ClassDigit is a Unicode decimal digit
+	ClassDigit
+	// ClassSpace is a Unicode white space, including newlines
+	ClassSpace
+	// ClassPunct is a Unicode punctuation or symbol, such as '_', '(' or '+'
+	ClassPunct
+)
+
+// PeekClass returns but does not consume the class of the next rune in the input
+func (l *Lexer) PeekClass() RuneClass {
+	switch r := l.Peek(); {
+	case r == eof:
+		return ClassEOF
+	case unicode.IsLetter(r):
+		return ClassLetter
+	case unicode.IsDigit(r):
+		return ClassDigit
+	case unicode.IsSpace(r):
+		return ClassSpace
+	case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		return ClassPunct
+	}
+	return ClassOther
+}
+
 // Backup steps back one rune. Can only be called once per call of Next
 func (l *Lexer) Backup() {
 	l.pos -= l.width
 }
 
+// halt emits an error token at pos and stops the scan:
+// Next returns EOF and nothing is emitted afterwards
+func (l *Lexer) halt(pos Pos, msg string) {
+	l.send(Token{Typ: TokError, Pos: pos, Val: msg})
+	l.halted = true
+	l.width = 0
+}
+
+// send delivers the token to the client, unless it is merged with adjacent ones
+func (l *Lexer) send(t Token) {
+	if l.halted {
+		return
+	}
+	l.steps = 0
+	if l.expect != "" {
+		if t.Typ == TokError {
+			t.Val += ": expected " + l.expect
+		}
+		l.expect = ""
+	}
+	if !l.coalesce[t.Typ] {
+		l.flush()
+		l.deliver(t)
+		return
+	}
+	end := t.Pos + Pos(len(t.Val))
+	if l.holding && l.held.Typ == t.Typ && l.held.Pos+Pos(len(l.held.Val)) == t.Pos &&
+		int(end) <= len(l.input) && l.input[t.Pos:end] == t.Val {
+		l.held.Val = l.input[l.held.Pos:end]
+		return
+	}
+	l.flush()
+	l.held, l.holding = t, true
+}
+
+// flush delivers the held token, if any
+func (l *Lexer) flush() {
+	if l.holding {
+		l.holding = false
+		l.deliver(l.held)
+	}
+}
+
+// deliver passes the token to the client
+func (l *Lexer) deliver(t Token) {
+	t.Pos = l.tokenPos(t.Pos)
+	switch {
+	case l.interner != nil:
+		t.Val = l.interner.Intern(t.Val)
+	case l.copyValues:
+		t.Val = strings.Clone(t.Val)
+	}
+	if l.valueHash != nil {
+		t.Hash = l.valueHash(t.Val)
+	}
+	if l.frequencies != nil && t.Typ != TokEOF && t.Typ != TokError {
+		l.frequencies.record(t.Val)
+	}
+	if l.tokens == nil {
+		l.queue = append(l.queue, t)
+	} else {
+		l.tokens <- t
+	}
+	if l.emitted++; l.yield != nil && l.emitted%l.yieldEvery == 0 {
+		l.yield()
+	}
+}
+
+// tokenPos maps a position in the scanned input to the position reported
+// to the client, undoing Normalize and adding the BaseOffset
+func (l *Lexer) tokenPos(pos Pos) Pos {
+	if l.normMap != nil {
+		pos = originalPos(l.normMap, pos)
+	}
+	return pos + l.baseOffset
+}
+
 // Emit passes an Token back to the client
 func (l *Lexer) Emit(t TokenType) {
-	l.tokens <- Token{t, l.start, l.input[l.start:l.pos]}
+	l.emit(Token{Typ: t})
+}
+
+// emit passes the token of the pending input back to the client
+func (l *Lexer) emit(t Token) {
+	t.Pos, t.Val = l.start, l.input[l.start:l.pos]
+	l.send(t)
 	l.start = l.pos
 }
 
+// EmitAt passes a token with explicit position and value back to the client
+// leaving the pending input intact. Consumers usually expect positions
+// of the tokens to be monotonic, so pos should not precede the last emitted token
+func (l *Lexer) EmitAt(t TokenType, pos Pos, val string) {
+	l.send(Token{Typ: t, Pos: pos, Val: val})
+}
+
+// Current returns the pending input of the current token.
+// The value is a slice of the input, so no copy is made
+func (l *Lexer) Current() string {
+	return l.input[l.start:l.pos]
+}
+
+// RewindToLastEmit discards the pending input of the current token
+// moving back to the point of the last Emit or Ignore
+func (l *Lexer) RewindToLastEmit() {
+	l.pos = l.start
+	l.width = 0
+}
+
+// MaxLookaheadObserved returns the longest distance, in bytes, the lexer has read
+// ahead of the start of the current token, which is the buffer size a streaming
+// source needs for the input. Called once the lexer is drained
+func (l *Lexer) MaxLookaheadObserved() int {
+	return int(l.maxLookahead)
+}
+
 // Ignore skips over the pending input before this point
 func (l *Lexer) Ignore() {
 	l.start = l.pos
+	l.steps = 0
 }
 
 // Errorf emits an error token and terminates the scan by passing
 // back a nil pointer that will be the next state, terminating l.NextToken
 func (l *Lexer) Errorf(format string, args ...interface{}) StateFn {
-	l.tokens <- Token{TokError, l.start, fmt.Sprintf(format, args...)}
+	l.send(Token{Typ: TokError, Pos: l.start, Val: fmt.Sprintf(format, args...)})
 	return nil
 }
 
+// Expect sets a description of what the state function expects next, such as
+// "')' to close parameter list", appended to the text of the next error token.
+// The hint is cleared when any token is emitted
+func (l *Lexer) Expect(description string) {
+	l.expect = description
+}
+
+// EmitUnterminated reports the pending input as a construct of type t
+// unterminated at the end of the input according to the policy set by
+// OnUnterminated: either by an error token with the formatted text,
+// or by a token flagged Unterminated followed by TokEOF.
+// It returns the next state, nil for the error
+func (l *Lexer) EmitUnterminated(t TokenType, format string, args ...interface{}) StateFn {
+	if l.unterminated == UnterminatedEmitFlagged {
+		l.emit(Token{Typ: t, Info: &TokenInfo{Unterminated: true}})
+		return EOF
+	}
+	return l.Errorf(format, args...)
+}
+
 // NextToken returns the next token from the input.
 // Called by the parser, not in the lexing goroutine
 func (l *Lexer) NextToken() Token {
-	return <-l.tokens
+	t, _ := l.next()
+	return t
+}
+
+// next returns the next token or false when the scan has finished
+func (l *Lexer) next() (Token, bool) {
+	if l.tokens != nil {
+		t, ok := <-l.tokens
+		return t, ok
+	}
+	for l.head == len(l.queue) {
+		if l.state == nil {
+			return Token{}, false
+		}
+		l.queue, l.head = l.queue[:0], 0
+		l.step()
+	}
+	t := l.queue[l.head]
+	l.head++
+	return t, true
 }
 
 // IgnoreRunes ignore all runes for which skip return true
@@ -165,6 +572,15 @@ func (l *Lexer) AcceptRun(set ...rune) bool {
 	return accepted
 }
 
+// AcceptRunSameLine consumes a run of runes from the valid set stopping at a newline,
+// even if the set contains it, so the run never spans lines.
+// It returns the number of runes consumed
+func (l *Lexer) AcceptRunSameLine(set ...rune) int {
+	return l.CountRunFunc(func(r rune) bool {
+		return r != '\n' && r != '\r' && indexRune(r, set...) >= 0
+	})
+}
+
 // AcceptUntil consumes a run of any runes except given.
 // It returns false when no runes were consumed
 func (l *Lexer) AcceptUntil(set ...rune) bool {
@@ -179,10 +595,185 @@ func (l *Lexer) AcceptUntil(set ...rune) bool {
 	}
 }
 
+// CountRun consumes a run of runes from the valid set.
+// It returns the number of runes (not bytes) consumed
+func (l *Lexer) CountRun(set ...rune) int {
+	n := 0
+	for indexRune(l.Next(), set...) >= 0 {
+		n++
+	}
+	l.Backup()
+	return n
+}
+
+// CountRunFunc consumes a run of runes for which accept returns true.
+// It returns the number of runes (not bytes) consumed
+func (l *Lexer) CountRunFunc(accept func(rune) bool) int {
+	n := 0
+	for {
+		r := l.Next()
+		if r == eof || !accept(r) {
+			l.Backup()
+			return n
+		}
+		n++
+	}
+}
+
+// CountUntil consumes a run of any runes except given.
+// It returns the number of runes (not bytes) consumed
+func (l *Lexer) CountUntil(set ...rune) int {
+	n := 0
+	for {
+		r := l.Next()
+		if indexRune(r, set...) >= 0 || r == eof {
+			l.Backup()
+			return n
+		}
+		n++
+	}
+}
+
+// EmitShebang emits the interpreter directive ("#!" line) as a token of type t.
+// The newline is not a part of the token. It returns false when
+// the lexer is not at the beginning of the input, following the byte order mark
+// skipped with SkipBOM, or there is no directive
+func (l *Lexer) EmitShebang(t TokenType) bool {
+	begin := Pos(0)
+	if l.skipBOM && strings.HasPrefix(l.input, bom) {
+		begin = Pos(len(bom))
+	}
+	if l.pos != begin || !strings.HasPrefix(l.input[begin:], "#!") {
+		return false
+	}
+	l.AcceptUntil('\n')
+	l.Emit(t)
+	return true
+}
+
+// AcceptRunHorizontalWhitespaceUnicode consumes a run of tabs and Unicode space
+// separators (category Zs), including no-break and thin spaces, but neither
+// newlines nor line and paragraph separators.
+// It returns the number of runes consumed
+func (l *Lexer) AcceptRunHorizontalWhitespaceUnicode() int {
+	return l.CountRunFunc(func(r rune) bool {
+		return r == '\t' || unicode.Is(unicode.Zs, r)
+	})
+}
+
+// AcceptRunUntilSentinel consumes a run of runes from runSet stopping at the first rune
+// that does not belong to it. It returns the rune the run stopped at, which is
+// a member of sentinels, EOFRune at the end of the input, or any other rune
+// outside of runSet, and the number of runes consumed. The stopping rune is not consumed
+func (l *Lexer) AcceptRunUntilSentinel(runSet []rune, sentinels []rune) (stoppedBy rune, consumed int) {
+	for {
+		r := l.Next()
+		if r == eof || indexRune(r, sentinels...) >= 0 || indexRune(r, runSet...) < 0 {
+			l.Backup()
+			return r, consumed
+		}
+		consumed++
+	}
+}
+
+// advance consumes the input up to the end position
+func (l *Lexer) advance(end Pos) {
+	for l.pos < end && l.Next() != eof {
+	}
+}
+
+// AcceptString consumes s if the input continues with it
+func (l *Lexer) AcceptString(s string) bool {
+	if !strings.HasPrefix(l.input[l.pos:], s) {
+		return false
+	}
+	end := l.pos + Pos(len(s))
+	l.advance(end)
+	return l.pos == end
+}
+
+// AcceptStringFold consumes the input matching s under Unicode simple case folding,
+// so "SELECT" matches "select" and "SeLeCt". The consumed input keeps its case.
+// It returns false, consuming nothing, when the input does not match
+func (l *Lexer) AcceptStringFold(s string) bool {
+	begin := l.pos
+	for _, want := range s {
+		if !equalFold(l.Next(), want) {
+			l.pos, l.width = begin, 0
+			return false
+		}
+	}
+	return true
+}
+
+// equalFold reports whether the runes are equal under simple case folding
+func equalFold(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for f := unicode.SimpleFold(a); f != a; f = unicode.SimpleFold(f) {
+		if f == b {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptPercentEncoded consumes a percent-encoded octet, "%" followed by exactly
+// two hexadecimal digits. It returns false, consuming nothing, for anything else,
+// including a malformed sequence to report
+func (l *Lexer) AcceptPercentEncoded() bool {
+	rest := l.input[l.pos:]
+	if len(rest) < 3 || rest[0] != '%' || !isHex(rest[1]) || !isHex(rest[2]) {
+		return false
+	}
+	l.advance(l.pos + 3)
+	return true
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// AcceptLineContinuation consumes a backslash immediately followed by a newline,
+// which continues the logical line onto the next physical one
+func (l *Lexer) AcceptLineContinuation() bool {
+	return l.AcceptString("\\\n") || l.AcceptString("\\\r\n")
+}
+
+// AtLogicalLineEnd reports whether the next rune ends the logical line:
+// it is a newline or the end of the input. A backslash continuing
+// the line is not a line end; consume it with AcceptLineContinuation
+func (l *Lexer) AtLogicalLineEnd() bool {
+	switch l.Peek() {
+	case '\n', '\r', eof:
+		return true
+	}
+	return false
+}
+
+// HasTrailingWhitespace reports whether a space or tab precedes the newline
+// or the end of the input ending the current line. It does not consume input
+func (l *Lexer) HasTrailingWhitespace() bool {
+	end := len(l.input)
+	if i := strings.IndexByte(l.input[l.pos:], '\n'); i >= 0 {
+		end = int(l.pos) + i
+	}
+	line := l.input[:end]
+	if i := strings.LastIndexByte(line, '\n'); i >= 0 {
+		line = line[i+1:]
+	}
+	line = strings.TrimSuffix(line, "\r")
+	return strings.HasSuffix(line, " ") || strings.HasSuffix(line, "\t")
+}
+
 // Drain drains the output so the lexing goroutine will exit.
 // Called by the parser, not in the lexing goroutine
 func (l *Lexer) Drain() {
-	for range l.tokens {
+	for {
+		if _, ok := l.next(); !ok {
+			return
+		}
 	}
 }
 
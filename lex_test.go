@@ -1 +1,511 @@
 package lex_test
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/redsift/lex"
+)
+
+const (
+	_ lex.TokenType = lex.FirstCustomToken + iota
+	tokWord
+	tokKeyword
+	tokIdent
+	tokSpace
+	tokIf
+	tokFor
+	tokFunc
+	tokText
+	tokActionStart
+	tokAction
+	tokActionEnd
+	tokComment
+	tokPunct
+	tokSemi
+)
+
+// collect runs the lexer till the end and returns all the tokens it emitted
+func collect(l *lex.Lexer) []lex.Token {
+	var tokens []lex.Token
+	for {
+		tok := l.NextToken()
+		if tok == (lex.Token{}) {
+			return tokens
+		}
+		tokens = append(tokens, tok)
+		if tok.Typ == lex.TokEOF || tok.Typ == lex.TokError {
+			l.Drain()
+			return tokens
+		}
+	}
+}
+
+// restAfter runs scan at the beginning of the input and returns
+// the input it left unconsumed
+func restAfter(input string, scan func(*lex.Lexer)) string {
+	var rest string
+	collect(lex.LexString(input, func(l *lex.Lexer) lex.StateFn {
+		scan(l)
+		l.Ignore()
+		l.AcceptUntil()
+		rest = l.Current()
+		return lex.EOF
+	}))
+	return rest
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		count func(*lex.Lexer) int
+		want  int
+		rest  string
+	}{
+		{"run", "ééé€x", func(l *lex.Lexer) int { return l.CountRun('é', '€') }, 4, "x"},
+		{"run empty", "xé", func(l *lex.Lexer) int { return l.CountRun('é') }, 0, "xé"},
+		{"run func", "日本語 x", func(l *lex.Lexer) int { return l.CountRunFunc(func(r rune) bool { return r != ' ' }) }, 3, " x"},
+		{"run func eof", "日本", func(l *lex.Lexer) int { return l.CountRunFunc(func(rune) bool { return true }) }, 2, ""},
+		{"until", "ü€ö;x", func(l *lex.Lexer) int { return l.CountUntil(';') }, 3, ";x"},
+		{"until eof", "ü€ö", func(l *lex.Lexer) int { return l.CountUntil(';') }, 3, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int
+			rest := restAfter(tt.input, func(l *lex.Lexer) { got = tt.count(l) })
+			if got != tt.want {
+				t.Errorf("count = %d, want %d", got, tt.want)
+			}
+			if rest != tt.rest {
+				t.Errorf("rest = %q, want %q", rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestEmitShebang(t *testing.T) {
+	const shebang = tokWord
+	tests := []struct {
+		input string
+		opts  []lex.Option
+		ok    bool
+		want  string
+		pos   lex.Pos
+	}{
+		{"#!/bin/sh -e\necho", nil, true, "#!/bin/sh -e", 0},
+		{"#!/usr/bin/env python", nil, true, "#!/usr/bin/env python", 0},
+		{"\uFEFF#!/bin/sh\n", []lex.Option{lex.SkipBOM()}, true, "#!/bin/sh", 3},
+		{"\uFEFF#!/bin/sh\n", nil, false, "", 0},
+		{"# comment\necho", nil, false, "", 0},
+		{"echo", nil, false, "", 0},
+	}
+	for _, tt := range tests {
+		var ok bool
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			ok = l.EmitShebang(shebang)
+			return lex.EOF
+		}, tt.opts...))
+		if ok != tt.ok {
+			t.Errorf("%q: EmitShebang() = %v, want %v", tt.input, ok, tt.ok)
+		}
+		if !tt.ok {
+			continue
+		}
+		if tokens[0].Typ != shebang || tokens[0].Val != tt.want || tokens[0].Pos != tt.pos {
+			t.Errorf("%q: got %v at %d, want %q", tt.input, tokens[0], tokens[0].Pos, tt.want)
+		}
+	}
+}
+
+func TestAcceptRunUntilSentinel(t *testing.T) {
+	digits := []rune("0123456789")
+	tests := []struct {
+		input    string
+		stop     rune
+		consumed int
+	}{
+		{"123;", ';', 3},
+		{"12,3", ',', 2},
+		{"123", lex.EOFRune, 3},
+		{";12", ';', 0},
+		{"12a;", 'a', 2},
+	}
+	for _, tt := range tests {
+		var (
+			stop     rune
+			consumed int
+		)
+		collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			stop, consumed = l.AcceptRunUntilSentinel(digits, []rune{';', ','})
+			if l.Peek() != stop {
+				t.Errorf("%q: stopping rune %q consumed", tt.input, stop)
+			}
+			return lex.EOF
+		}))
+		if stop != tt.stop || consumed != tt.consumed {
+			t.Errorf("%q: got (%q, %d), want (%q, %d)", tt.input, stop, consumed, tt.stop, tt.consumed)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	var current []string
+	collect(lex.LexString("ab日本 cd", func(l *lex.Lexer) lex.StateFn {
+		current = append(current, l.Current())
+		l.AcceptUntil(' ')
+		current = append(current, l.Current())
+		l.Emit(tokWord)
+		current = append(current, l.Current())
+		l.Next()
+		current = append(current, l.Current())
+		return lex.EOF
+	}))
+	want := []string{"", "ab日本", "", " "}
+	for i := range want {
+		if current[i] != want[i] {
+			t.Errorf("Current() #%d = %q, want %q", i, current[i], want[i])
+		}
+	}
+}
+
+func BenchmarkCurrent(b *testing.B) {
+	b.ReportAllocs()
+	collect(lex.LexString("identifier", func(l *lex.Lexer) lex.StateFn {
+		l.AcceptUntil()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if len(l.Current()) == 0 {
+				b.Fatal("empty token")
+			}
+		}
+		return lex.EOF
+	}))
+}
+
+func TestEmitAt(t *testing.T) {
+	const openBlock = tokWord + 1
+	tokens := collect(lex.LexString("if x {", func(l *lex.Lexer) lex.StateFn {
+		l.AcceptUntil(' ')
+		l.Emit(tokWord)
+		l.EmitAt(openBlock, 5, "")
+		l.AcceptUntil('{')
+		l.Emit(tokWord)
+		return lex.EOF
+	}))
+	want := []lex.Token{
+		{Typ: tokWord, Pos: 0, Val: "if"},
+		{Typ: openBlock, Pos: 5, Val: ""},
+		{Typ: tokWord, Pos: 2, Val: " x "},
+		{Typ: lex.TokEOF, Pos: 5, Val: ""},
+	}
+	if diff := lex.DiffTokens(tokens, want, lex.TokenCmpOptions{}); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRewindToLastEmit(t *testing.T) {
+	tokens := collect(lex.LexString("ab cd", func(l *lex.Lexer) lex.StateFn {
+		l.AcceptUntil(' ')
+		l.Emit(tokWord)
+		l.RewindToLastEmit() // nothing scanned since the emit
+		l.AcceptUntil()
+		l.RewindToLastEmit()
+		l.Accept(' ')
+		l.Ignore()
+		l.AcceptUntil()
+		l.Emit(tokWord)
+		return lex.EOF
+	}))
+	want := []lex.Token{
+		{Typ: tokWord, Pos: 0, Val: "ab"},
+		{Typ: tokWord, Pos: 3, Val: "cd"},
+		{Typ: lex.TokEOF, Pos: 5, Val: ""},
+	}
+	if diff := lex.DiffTokens(tokens, want, lex.TokenCmpOptions{}); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// lexLogicalLines emits logical lines joining the continued ones
+func lexLogicalLines(l *lex.Lexer) lex.StateFn {
+	for !l.AtLogicalLineEnd() {
+		if !l.AcceptLineContinuation() {
+			l.Next()
+		}
+	}
+	l.Emit(tokWord)
+	if !l.Accept('\r', '\n') {
+		return lex.EOF
+	}
+	l.Accept('\n')
+	l.Ignore()
+	return lexLogicalLines
+}
+
+func TestLineContinuation(t *testing.T) {
+	tokens := collect(lex.LexString("a \\\nb \\\r\nc\nd \\ e\\", lexLogicalLines))
+	want := []string{"a \\\nb \\\r\nc", "d \\ e\\"}
+	if len(tokens) != len(want)+1 {
+		t.Fatalf("got %v, want %q", tokens, want)
+	}
+	for i := range want {
+		if tokens[i].Val != want[i] {
+			t.Errorf("line %d = %q, want %q", i, tokens[i].Val, want[i])
+		}
+	}
+}
+
+func TestAcceptRunHorizontalWhitespaceUnicode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+		rest  string
+	}{
+		{" \t\u00a0\u2009\u3000x", 5, "x"},
+		{"\u00a0\u00a0\u2028next", 2, "\u2028next"},
+		{"\t \r\n", 2, "\r\n"},
+		{" \n", 1, "\n"},
+		{"\u2029", 0, "\u2029"},
+		{"x", 0, "x"},
+	}
+	for _, tt := range tests {
+		var got int
+		rest := restAfter(tt.input, func(l *lex.Lexer) { got = l.AcceptRunHorizontalWhitespaceUnicode() })
+		if got != tt.want {
+			t.Errorf("%q: consumed %d runes, want %d", tt.input, got, tt.want)
+		}
+		if rest != tt.rest {
+			t.Errorf("%q: rest = %q, want %q", tt.input, rest, tt.rest)
+		}
+	}
+}
+
+func TestPeekClass(t *testing.T) {
+	tests := []struct {
+		input string
+		want  lex.RuneClass
+	}{
+		{"", lex.ClassEOF},
+		{"a", lex.ClassLetter},
+		{"Ж1", lex.ClassLetter},
+		{"日", lex.ClassLetter},
+		{"7", lex.ClassDigit},
+		{"\u0663", lex.ClassDigit},
+		{" ", lex.ClassSpace},
+		{"\n", lex.ClassSpace},
+		{"\u00a0", lex.ClassSpace},
+		{"(", lex.ClassPunct},
+		{"_", lex.ClassPunct},
+		{"+", lex.ClassPunct},
+		{"\x00", lex.ClassOther},
+	}
+	for _, tt := range tests {
+		var got lex.RuneClass
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			got = l.PeekClass()
+			return lex.EOF
+		}))
+		if got != tt.want {
+			t.Errorf("%q: PeekClass() = %d, want %d", tt.input, got, tt.want)
+		}
+		if tokens[0].Pos != 0 {
+			t.Errorf("%q: PeekClass consumed input", tt.input)
+		}
+	}
+}
+
+func TestMaxLookaheadObserved(t *testing.T) {
+	input := "short " + strings.Repeat("x", 100) + ";" + strings.Repeat("y", 50)
+	l := lex.LexString(input, func(l *lex.Lexer) lex.StateFn {
+		l.AcceptUntil(' ')
+		l.Emit(tokWord)
+		l.AcceptUntil(';')
+		l.RewindToLastEmit()
+		l.Next()
+		l.Ignore()
+		l.AcceptUntil()
+		l.Emit(tokWord)
+		return lex.EOF
+	})
+	collect(l)
+	if got, want := l.MaxLookaheadObserved(), len(input)-len("short "); got != want {
+		t.Errorf("MaxLookaheadObserved() = %d, want %d", got, want)
+	}
+}
+
+func TestAcceptPercentEncoded(t *testing.T) {
+	tests := []struct {
+		input string
+		ok    bool
+		rest  string
+	}{
+		{"%2F", true, ""},
+		{"%2fpath", true, "path"},
+		{"%aB%", true, "%"},
+		{"%2", false, "%2"},
+		{"%", false, "%"},
+		{"%ZZ", false, "%ZZ"},
+		{"%2G", false, "%2G"},
+		{"2F", false, "2F"},
+	}
+	for _, tt := range tests {
+		var ok bool
+		rest := restAfter(tt.input, func(l *lex.Lexer) { ok = l.AcceptPercentEncoded() })
+		if ok != tt.ok || rest != tt.rest {
+			t.Errorf("%q: got (%v, rest %q), want (%v, rest %q)", tt.input, ok, rest, tt.ok, tt.rest)
+		}
+	}
+}
+
+func TestAcceptRunSameLine(t *testing.T) {
+	tests := []struct {
+		input string
+		set   string
+		want  int
+		rest  string
+	}{
+		{"aab\nab", "ab\n", 3, "\nab"},
+		{"aab\nab", "ab", 3, "\nab"},
+		{"ab\r\nab", "ab\r\n", 2, "\r\nab"},
+		{"äöü x", "äöü", 3, " x"},
+		{"\nab", "ab\n", 0, "\nab"},
+	}
+	for _, tt := range tests {
+		var got int
+		rest := restAfter(tt.input, func(l *lex.Lexer) { got = l.AcceptRunSameLine([]rune(tt.set)...) })
+		if got != tt.want || rest != tt.rest {
+			t.Errorf("%q with %q: got (%d, rest %q), want (%d, rest %q)", tt.input, tt.set, got, rest, tt.want, tt.rest)
+		}
+	}
+}
+
+func TestAcceptString(t *testing.T) {
+	tests := []struct {
+		input string
+		s     string
+		fold  bool
+		ok    bool
+		rest  string
+	}{
+		{"select x", "select", false, true, " x"},
+		{"SELECT x", "select", false, false, "SELECT x"},
+		{"SELECT x", "select", true, true, " x"},
+		{"select", "SELECT", true, true, ""},
+		{"SeLeCt", "select", true, true, ""},
+		{"ΣΊΣΥΦΟΣ", "σίσυφος", true, true, ""},
+		{"straße", "STRASSE", true, false, "straße"},
+		{"selec", "select", true, false, "selec"},
+		{"selekt", "select", true, false, "selekt"},
+	}
+	for _, tt := range tests {
+		var (
+			ok       bool
+			consumed string
+		)
+		rest := restAfter(tt.input, func(l *lex.Lexer) {
+			if tt.fold {
+				ok = l.AcceptStringFold(tt.s)
+			} else {
+				ok = l.AcceptString(tt.s)
+			}
+			consumed = l.Current()
+		})
+		if ok != tt.ok || rest != tt.rest {
+			t.Errorf("%q matching %q: got (%v, rest %q), want (%v, rest %q)", tt.input, tt.s, ok, rest, tt.ok, tt.rest)
+		}
+		if ok && consumed != tt.input[:len(tt.input)-len(tt.rest)] {
+			t.Errorf("%q matching %q: consumed %q", tt.input, tt.s, consumed)
+		}
+	}
+}
+
+func TestTokenRuneOffset(t *testing.T) {
+	input := "na\u00efve \u65e5\u672c x"
+	tokens := collect(lex.LexString(input, lexWords))
+	want := []struct {
+		pos  lex.Pos
+		rune int
+	}{{0, 0}, {7, 6}, {14, 9}, {15, 10}}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(want))
+	}
+	for i, w := range want {
+		if tokens[i].Pos != w.pos || tokens[i].RuneOffset(input) != w.rune {
+			t.Errorf("token %d: got pos %d rune offset %d, want %d and %d", i, tokens[i].Pos, tokens[i].RuneOffset(input), w.pos, w.rune)
+		}
+	}
+	if got := (lex.Token{Pos: 100}).RuneOffset(input); got != 10 {
+		t.Errorf("RuneOffset beyond input = %d, want 10", got)
+	}
+}
+
+func TestHasTrailingWhitespace(t *testing.T) {
+	input := "clean\ntrailing  \n\t\nmixed \t\r\ncrlf\r\n\nlast "
+	var got []bool
+	collect(lex.LexString(input, func(l *lex.Lexer) lex.StateFn {
+		for {
+			l.AcceptUntil('\n')
+			got = append(got, l.HasTrailingWhitespace())
+			if !l.Accept('\n') {
+				return lex.EOF
+			}
+		}
+	}))
+	want := []bool{false, true, true, true, false, false, true}
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: HasTrailingWhitespace() = %v, want %v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestExpect(t *testing.T) {
+	// lexParams scans a parameter list such as (a, b)
+	lexParams := func(l *lex.Lexer) lex.StateFn {
+		l.Expect("'(' to open parameter list")
+		if !l.Accept('(') {
+			return l.Errorf("unexpected %q", l.Peek())
+		}
+		l.Emit(tokPunct)
+		for l.AcceptRun('a', 'b', 'c', ',', ' ') {
+			l.Emit(tokIdent)
+		}
+		l.Expect("')' to close parameter list")
+		if !l.Accept(')') {
+			return l.Errorf("unexpected %q", l.Peek())
+		}
+		l.Emit(tokPunct)
+		return l.Errorf("done")
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"(a, b;", `unexpected ';': expected ')' to close parameter list`},
+		{"a", `unexpected 'a': expected '(' to open parameter list`},
+		{"(a)", "done"},
+	}
+	for _, tt := range tests {
+		tokens := collect(lex.LexString(tt.input, lexParams))
+		if last := tokens[len(tokens)-1]; last.Typ != lex.TokError || last.Val != tt.want {
+			t.Errorf("%q: got %v, want error %q", tt.input, last, tt.want)
+		}
+	}
+}
+
+func TestTokenSize(t *testing.T) {
+	// Details of few tokens are kept in TokenInfo so plain tokens stay small
+	if size := unsafe.Sizeof(lex.Token{}); size > 48 {
+		t.Errorf("Token takes %d bytes, want at most 48", size)
+	}
+	for _, tok := range lex.LexAll("a b", lexWords) {
+		if tok.Info != nil {
+			t.Errorf("%v has info %+v", tok, *tok.Info)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package lex
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Normalize makes the lexer scan the input transformed by normalizer, e.g.
+// norm.NFC.String from golang.org/x/text/unicode/norm, so that tokens spelled
+// in different normalization forms get identical values.
+// Positions of emitted tokens still refer to the original input.
+//
+// The normalizer is applied to each non-ASCII character together with
+// the combining marks following it, so it must not compose across such clusters
+func Normalize(normalizer func(string) string) Option {
+	return func(l *Lexer) {
+		l.normalize = normalizer
+	}
+}
+
+// posMapping is a point where offsets in the transformed input
+// and in the original input start to advance together again
+type posMapping struct {
+	norm Pos
+	orig Pos
+}
+
+// combining reports whether r attaches to the preceding rune
+func combining(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me) ||
+		r >= 0x1160 && r <= 0x11ff // Hangul medial vowels and final consonants
+}
+
+// normalizeInput normalizes input cluster by cluster
+// and tracks the clusters which changed their length
+func normalizeInput(input string, normalizer func(string) string) (string, []posMapping) {
+	var (
+		b       strings.Builder
+		mapping = []posMapping{{0, 0}}
+	)
+	b.Grow(len(input))
+	for i := 0; i < len(input); {
+		_, w := utf8.DecodeRuneInString(input[i:])
+		end := i + w
+		for end < len(input) {
+			r, w := utf8.DecodeRuneInString(input[end:])
+			if !combining(r) {
+				break
+			}
+			end += w
+		}
+		cluster := input[i:end]
+		if len(cluster) == 1 && cluster[0] < utf8.RuneSelf {
+			b.WriteByte(cluster[0])
+			i = end
+			continue
+		}
+		normalized := normalizer(cluster)
+		if len(normalized) != len(cluster) {
+			mapping = append(mapping, posMapping{Pos(b.Len()), Pos(i)})
+			b.WriteString(normalized)
+			mapping = append(mapping, posMapping{Pos(b.Len()), Pos(end)})
+		} else {
+			b.WriteString(normalized)
+		}
+		i = end
+	}
+	return b.String(), mapping
+}
+
+// originalPos maps the position in the transformed input to the original one.
+// Positions within a transformed part past its original length map to its start
+func originalPos(mapping []posMapping, pos Pos) Pos {
+	i := sort.Search(len(mapping), func(i int) bool { return mapping[i].norm > pos }) - 1
+	orig := mapping[i].orig + pos - mapping[i].norm
+	if i+1 < len(mapping) && orig >= mapping[i+1].orig {
+		orig = mapping[i].orig
+	}
+	return orig
+}
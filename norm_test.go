@@ -0,0 +1,40 @@
+package lex_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+// composeAcute stands in for norm.NFC.String in tests
+var composeAcute = strings.NewReplacer("e\u0301", "\u00e9", "E\u0301", "\u00c9").Replace
+
+// lexWords emits runs of non-space runes as tokens
+func lexWords(l *lex.Lexer) lex.StateFn {
+	l.IgnoreRunes(func(r rune) bool { return r == ' ' })
+	if !l.AcceptUntil(' ') {
+		return lex.EOF
+	}
+	l.Emit(tokWord)
+	return lexWords
+}
+
+func TestNormalize(t *testing.T) {
+	precomposed := collect(lex.LexString("x caf\u00e9 y", lexWords))
+	decomposed := collect(lex.LexString("x cafe\u0301 y", lexWords, lex.Normalize(composeAcute)))
+	if len(precomposed) != len(decomposed) {
+		t.Fatalf("got %d tokens, want %d", len(decomposed), len(precomposed))
+	}
+	for i := range precomposed {
+		if precomposed[i].Val != decomposed[i].Val {
+			t.Errorf("token %d = %q, want %q", i, decomposed[i].Val, precomposed[i].Val)
+		}
+	}
+	wantPos := []lex.Pos{0, 2, 9, 10}
+	for i, want := range wantPos {
+		if decomposed[i].Pos != want {
+			t.Errorf("token %d at %d, want %d", i, decomposed[i].Pos, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package lex
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ScanIntBounded consumes a decimal integer, with an optional sign when signed is true,
+// and reports whether its value overflows an integer of bitSize bits, 0 meaning the size of int.
+// All the digits are consumed regardless of the overflow.
+// It returns false, consuming nothing, when there are no digits
+func ScanIntBounded(l *Lexer, bitSize int, signed bool) (ok bool, overflow bool) {
+	if bitSize <= 0 || bitSize > 64 {
+		bitSize = strconv.IntSize
+	}
+	begin := l.pos
+	limit := uint64(1)<<(bitSize-1) - 1 + uint64(1)<<(bitSize-1) // avoids overflowing 1<<64
+	if signed {
+		limit = uint64(1)<<(bitSize-1) - 1
+		if l.Accept('-') {
+			limit++
+		} else {
+			l.Accept('+')
+		}
+	}
+	var v uint64
+	digits := 0
+	for {
+		r := l.Next()
+		if r < '0' || r > '9' {
+			l.Backup()
+			break
+		}
+		digits++
+		d := uint64(r - '0')
+		if overflow || d > limit || v > (limit-d)/10 {
+			overflow = true
+			continue
+		}
+		v = v*10 + d
+	}
+	if digits == 0 {
+		l.pos, l.width = begin, 0
+		return false, false
+	}
+	return true, overflow
+}
+
+// NumberFormat describes separators of decimal numbers
+type NumberFormat struct {
+	Decimal  rune // Separator of the fractional part
+	Grouping rune // Separator of digit groups in the integer part, 0 for none
+	Raw      bool // Leave Cooked empty, e.g. for formatters needing the source only
+}
+
+var (
+	// USNumbers formats numbers as 1,234.56
+	USNumbers = NumberFormat{Decimal: '.', Grouping: ','}
+	// EUNumbers formats numbers as 1.234,56
+	EUNumbers = NumberFormat{Decimal: ',', Grouping: '.'}
+)
+
+// ScanNumber consumes a decimal number in the format f and emits it as a token
+// of type t with the value normalized in Cooked, unless f.Raw is set: without
+// grouping separators and "_" separating digits, as in 1_000.5, and with "."
+// separating the fractional part. A grouping separator is consumed only when
+// followed by a group of three digits, so "3,14" is 3 in USNumbers, and
+// other separators only when followed by a digit.
+// It returns false, consuming nothing, when not at a digit
+func ScanNumber(l *Lexer, f NumberFormat, t TokenType) bool {
+	var cooked *strings.Builder
+	if !f.Raw {
+		cooked = new(strings.Builder)
+	}
+	if !scanDigits(l, cooked, f.Grouping) {
+		return false
+	}
+	if next := l.input[l.pos:]; strings.HasPrefix(next, string(f.Decimal)) {
+		if rest := next[len(string(f.Decimal)):]; rest != "" && isDigit(rest[0]) {
+			l.Next()
+			if cooked != nil {
+				cooked.WriteByte('.')
+			}
+			scanDigits(l, cooked, 0)
+		}
+	}
+	tok := Token{Typ: t}
+	if cooked != nil {
+		tok.Info = &TokenInfo{Cooked: cooked.String()}
+	}
+	l.emit(tok)
+	return true
+}
+
+// scanDigits consumes a run of digits separated by "_" or by group followed
+// by exactly three digits, and writes the digits to b, unless it is nil
+func scanDigits(l *Lexer, b *strings.Builder, group rune) bool {
+	begin := l.pos
+	for {
+		r := l.Next()
+		switch {
+		case r >= '0' && r <= '9':
+			if b != nil {
+				b.WriteRune(r)
+			}
+			continue
+		case l.pos-l.width == begin:
+		case r == '_' && int(l.pos) < len(l.input) && isDigit(l.input[l.pos]):
+			continue
+		case r == group && r != 0 && isGroup(l.input[l.pos:]):
+			continue
+		}
+		l.Backup()
+		return l.pos > begin
+	}
+}
+
+// isGroup reports whether s starts with three digits not followed by another one
+func isGroup(s string) bool {
+	if len(s) < 3 || !isDigit(s[0]) || !isDigit(s[1]) || !isDigit(s[2]) {
+		return false
+	}
+	return len(s) == 3 || !isDigit(s[3])
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
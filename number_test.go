@@ -0,0 +1,105 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestScanIntBounded(t *testing.T) {
+	tests := []struct {
+		input    string
+		bitSize  int
+		signed   bool
+		ok       bool
+		overflow bool
+		val      string
+	}{
+		{"9223372036854775807", 64, true, true, false, "9223372036854775807"},
+		{"9223372036854775808", 64, true, true, true, "9223372036854775808"},
+		{"-9223372036854775808", 64, true, true, false, "-9223372036854775808"},
+		{"-9223372036854775809", 64, true, true, true, "-9223372036854775809"},
+		{"+12;", 64, true, true, false, "+12"},
+		{"18446744073709551615", 64, false, true, false, "18446744073709551615"},
+		{"18446744073709551616", 64, false, true, true, "18446744073709551616"},
+		{"99999999999999999999999 ", 64, false, true, true, "99999999999999999999999"},
+		{"127", 8, true, true, false, "127"},
+		{"128", 8, true, true, true, "128"},
+		{"-128", 8, true, true, false, "-128"},
+		{"-129", 8, true, true, true, "-129"},
+		{"255", 8, false, true, false, "255"},
+		{"256", 8, false, true, true, "256"},
+		{"-1", 8, false, false, false, ""},
+		{"2", 1, false, true, true, "2"},
+		{"1", 1, false, true, false, "1"},
+		{"-x", 64, true, false, false, ""},
+		{"x", 64, true, false, false, ""},
+	}
+	for _, tt := range tests {
+		var ok, overflow bool
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			ok, overflow = lex.ScanIntBounded(l, tt.bitSize, tt.signed)
+			l.Emit(tokWord)
+			return lex.EOF
+		}))
+		if ok != tt.ok || overflow != tt.overflow {
+			t.Errorf("%q/%d: got (%v, %v), want (%v, %v)", tt.input, tt.bitSize, ok, overflow, tt.ok, tt.overflow)
+		}
+		if tokens[0].Val != tt.val {
+			t.Errorf("%q/%d: consumed %q, want %q", tt.input, tt.bitSize, tokens[0].Val, tt.val)
+		}
+	}
+}
+
+func TestScanNumber(t *testing.T) {
+	tests := []struct {
+		input  string
+		format lex.NumberFormat
+		ok     bool
+		val    string
+		cooked string
+	}{
+		{"1,234.56", lex.USNumbers, true, "1,234.56", "1234.56"},
+		{"1.234,56", lex.EUNumbers, true, "1.234,56", "1234.56"},
+		{"1.234.567", lex.EUNumbers, true, "1.234.567", "1234567"},
+		{"3.14", lex.USNumbers, true, "3.14", "3.14"},
+		{"3,14", lex.EUNumbers, true, "3,14", "3.14"},
+		{"3,14", lex.USNumbers, true, "3", "3"},
+		{"1,2,3", lex.USNumbers, true, "1", "1"},
+		{"1,2345", lex.USNumbers, true, "1", "1"},
+		{"12,345,678", lex.USNumbers, true, "12,345,678", "12345678"},
+		{"1,234,56", lex.USNumbers, true, "1,234", "1234"},
+		{"42, 7", lex.USNumbers, true, "42", "42"},
+		{"42.", lex.USNumbers, true, "42", "42"},
+		{"1,234.5.6", lex.USNumbers, true, "1,234.5", "1234.5"},
+		{"1.234,5,6", lex.EUNumbers, true, "1.234,5", "1234.5"},
+		{"12", lex.NumberFormat{Decimal: '.'}, true, "12", "12"},
+		{"1\u202f234,5", lex.NumberFormat{Decimal: ',', Grouping: '\u202f'}, true, "1\u202f234,5", "1234.5"},
+		{"1_000.5", lex.USNumbers, true, "1_000.5", "1000.5"},
+		{"1_000,000_1", lex.EUNumbers, true, "1_000,000_1", "1000.0001"},
+		{"1_,5", lex.EUNumbers, true, "1", "1"},
+		{"1__0", lex.USNumbers, true, "1", "1"},
+		{"1_000.5", lex.NumberFormat{Decimal: '.', Raw: true}, true, "1_000.5", ""},
+		{",5", lex.USNumbers, false, "", ""},
+		{"x", lex.USNumbers, false, "", ""},
+	}
+	for _, tt := range tests {
+		var ok bool
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			ok = lex.ScanNumber(l, tt.format, tokWord)
+			return lex.EOF
+		}))
+		if ok != tt.ok {
+			t.Errorf("%q: ScanNumber() = %v, want %v", tt.input, ok, tt.ok)
+		}
+		if !tt.ok {
+			if tokens[0].Typ != lex.TokEOF || tokens[0].Pos != 0 {
+				t.Errorf("%q: consumed input before %v", tt.input, tokens[0])
+			}
+			continue
+		}
+		if tokens[0].Val != tt.val || tokens[0].Cooked() != tt.cooked || tokens[0].Pos != 0 {
+			t.Errorf("%q: got %q cooked %q, want %q cooked %q", tt.input, tokens[0].Val, tokens[0].Cooked(), tt.val, tt.cooked)
+		}
+	}
+}
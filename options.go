@@ -0,0 +1,168 @@
+package lex
+
+import "hash/fnv"
+
+// CopyValues makes Emit copy values of the tokens instead of slicing the input.
+// By default values share memory with the input, so any token the client holds
+// keeps the whole input alive; with CopyValues the input can be garbage collected
+// once lexing completes
+func CopyValues() Option {
+	return func(l *Lexer) {
+		l.copyValues = true
+	}
+}
+
+// MaxInputBytes limits the number of input bytes the lexer may consume,
+// counted in the input given to LexString before Normalize or ExpandTabs.
+// Reading past the limit emits a TokError "input too large" and stops the scan
+func MaxInputBytes(n int) Option {
+	return func(l *Lexer) {
+		l.maxInputBytes = Pos(n)
+	}
+}
+
+// BaseOffset shifts positions of emitted tokens by base, so that tokens
+// of a fragment refer to the document the fragment was extracted from
+func BaseOffset(base Pos) Option {
+	return func(l *Lexer) {
+		l.baseOffset = base
+	}
+}
+
+// MaxRune limits runes allowed in the input, e.g. to unicode.MaxASCII.
+// Reading a rune above the limit emits a TokError at its position and stops the scan
+func MaxRune(max rune) Option {
+	return func(l *Lexer) {
+		l.maxRune = max
+	}
+}
+
+// SkipBOM skips the UTF-8 byte order mark at the beginning of the input
+func SkipBOM() Option {
+	return func(l *Lexer) {
+		l.skipBOM = true
+	}
+}
+
+// RejectBOM reports a byte order mark anywhere but at the beginning of the input,
+// which usually results from concatenating files. Reading such a mark emits
+// a TokError "unexpected BOM" at its position and stops the scan
+func RejectBOM() Option {
+	return func(l *Lexer) {
+		l.rejectBOM = true
+	}
+}
+
+// WithValueHash sets Hash of emitted tokens to h of their values,
+// 64-bit FNV-1a when h is nil
+func WithValueHash(h func(string) uint64) Option {
+	if h == nil {
+		h = fnv1a
+	}
+	return func(l *Lexer) {
+		l.valueHash = h
+	}
+}
+
+func fnv1a(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// Coalesce merges adjacent tokens of the same type, one of types, into one spanning
+// them all, e.g. fragments of text split by a scanner. A token is merged when
+// it starts where the previous one ends and its value is the input in between.
+// As merging requires holding tokens back, they are delivered to the client
+// once a token of another type is emitted or the scan finishes
+func Coalesce(types ...TokenType) Option {
+	return func(l *Lexer) {
+		if l.coalesce == nil {
+			l.coalesce = make(map[TokenType]bool)
+		}
+		for _, t := range types {
+			l.coalesce[t] = true
+		}
+	}
+}
+
+// UnterminatedPolicy defines how EmitUnterminated reports constructs,
+// such as strings or comments, cut short by the end of the input
+type UnterminatedPolicy int
+
+const (
+	// UnterminatedError reports an unterminated construct with TokError
+	UnterminatedError UnterminatedPolicy = iota
+	// UnterminatedEmitFlagged emits an unterminated construct as a token flagged
+	// Unterminated followed by TokEOF, which suits editors highlighting partial input
+	UnterminatedEmitFlagged
+)
+
+// OnUnterminated sets the policy for unterminated constructs, UnterminatedError by default
+func OnUnterminated(policy UnterminatedPolicy) Option {
+	return func(l *Lexer) {
+		l.unterminated = policy
+	}
+}
+
+// YieldEvery calls fn after every n emitted tokens, letting a single-threaded
+// environment, such as WebAssembly, yield control during long scans
+func YieldEvery(n int, fn func()) Option {
+	return func(l *Lexer) {
+		if n > 0 {
+			l.yieldEvery, l.yield = n, fn
+		}
+	}
+}
+
+// BOFToken emits a zero-width token of type t at position 0 before
+// the first state function runs, marking the beginning of the input
+func BOFToken(t TokenType) Option {
+	return func(l *Lexer) {
+		l.bof = t
+	}
+}
+
+// PreciseUntil replaces the state functions with coarse once a state returns
+// with the start of the next token at or past limit, so previews of huge inputs
+// only tokenize what is shown. The limit is a position in the scanned input,
+// before the BaseOffset is added and Normalize positions are mapped back.
+// CoarseText makes a suitable coarse state
+func PreciseUntil(limit Pos, coarse StateFn) Option {
+	return func(l *Lexer) {
+		l.preciseUntil, l.coarse = limit, coarse
+	}
+}
+
+// CoarseText returns a state emitting the rest of the input as a single
+// token of type t, if not empty, followed by TokEOF
+func CoarseText(t TokenType) StateFn {
+	return func(l *Lexer) StateFn {
+		l.advance(Pos(len(l.input)))
+		if l.pos > l.start {
+			l.Emit(t)
+		}
+		return EOF
+	}
+}
+
+// OnProgress calls fn from Next about every everyBytes bytes of input read
+// with the current position, mapped like those of tokens, and its line number,
+// counted from 1, for progress UIs
+func OnProgress(everyBytes int, fn func(pos Pos, line int)) Option {
+	return func(l *Lexer) {
+		if everyBytes > 0 {
+			l.progress, l.progressEvery = fn, Pos(everyBytes)
+			l.nextProgress, l.progressLine = Pos(everyBytes), 1
+		}
+	}
+}
+
+// MaxStepsPerToken stops the scan with an error once n calls of Next or Peek
+// are made without emitting a token or ignoring input. The running state
+// function is aborted, so even one looping forever on adversarial input ends
+func MaxStepsPerToken(n int) Option {
+	return func(l *Lexer) {
+		l.maxSteps = n
+	}
+}
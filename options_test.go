@@ -0,0 +1,321 @@
+package lex_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unsafe"
+
+	"github.com/redsift/lex"
+)
+
+// sharesMemory reports whether s points into the memory of input
+func sharesMemory(s, input string) bool {
+	p := uintptr(unsafe.Pointer(unsafe.StringData(s)))
+	begin := uintptr(unsafe.Pointer(unsafe.StringData(input)))
+	return p >= begin && p < begin+uintptr(len(input))
+}
+
+func TestCopyValues(t *testing.T) {
+	input := strings.Repeat("word ", 100)
+	for _, copyValues := range []bool{false, true} {
+		var opts []lex.Option
+		if copyValues {
+			opts = append(opts, lex.CopyValues())
+		}
+		tokens := collect(lex.LexString(input, lexWords, opts...))
+		if tokens[0].Val != "word" {
+			t.Fatalf("got %q, want %q", tokens[0].Val, "word")
+		}
+		if shares := sharesMemory(tokens[0].Val, input); shares == copyValues {
+			t.Errorf("CopyValues %v: value shares memory with the input: %v", copyValues, shares)
+		}
+	}
+}
+
+// BenchmarkCopyValues shows the cost of copying: values are allocated
+// per token, while shared ones cost nothing but retain the input
+func BenchmarkCopyValues(b *testing.B) {
+	input := strings.Repeat("word ", 1000)
+	for _, bb := range []struct {
+		name string
+		opts []lex.Option
+	}{
+		{"shared", nil},
+		{"copied", []lex.Option{lex.CopyValues()}},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				collect(lex.LexString(input, lexWords, bb.opts...))
+			}
+		})
+	}
+}
+
+func TestMaxInputBytes(t *testing.T) {
+	tokens := collect(lex.LexString("one two three", lexWords, lex.MaxInputBytes(9)))
+	want := []lex.Token{
+		{Typ: tokWord, Pos: 0, Val: "one"},
+		{Typ: tokWord, Pos: 4, Val: "two"},
+		{Typ: lex.TokError, Pos: 9, Val: "input too large"},
+	}
+	if diff := lex.DiffTokens(tokens, want, lex.TokenCmpOptions{}); diff != "" {
+		t.Error(diff)
+	}
+
+	tokens = collect(lex.LexString("one two", lexWords, lex.MaxInputBytes(7)))
+	if last := tokens[len(tokens)-1]; last.Typ != lex.TokEOF {
+		t.Errorf("input within the limit: got %v, want EOF", last)
+	}
+}
+
+func TestBaseOffset(t *testing.T) {
+	tokens := collect(lex.LexString("one  two", lexWords, lex.BaseOffset(1234)))
+	want := []lex.Pos{1234, 1239, 1242}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %v, want %d tokens", tokens, len(want))
+	}
+	for i := range want {
+		if tokens[i].Pos != want[i] {
+			t.Errorf("token %v at %d, want %d", tokens[i], tokens[i].Pos, want[i])
+		}
+	}
+}
+
+func TestMaxRune(t *testing.T) {
+	tokens := collect(lex.LexString("Host: Zürich", lexWords, lex.MaxRune(unicode.MaxASCII)))
+	last := tokens[len(tokens)-1]
+	want := lex.Token{Typ: lex.TokError, Pos: 7, Val: "rune U+00FC is out of the allowed range"}
+	if last != want {
+		t.Errorf("got %#v, want %#v", last, want)
+	}
+	if len(tokens) != 2 || tokens[0].Val != "Host:" {
+		t.Errorf("got %v, want the first word and the error", tokens)
+	}
+
+	tokens = collect(lex.LexString("Host: Zurich", lexWords, lex.MaxRune(unicode.MaxASCII)))
+	if last := tokens[len(tokens)-1]; last.Typ != lex.TokEOF {
+		t.Errorf("ASCII input: got %v, want EOF", last)
+	}
+}
+
+func TestBOM(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []lex.Option
+		want  []lex.Token
+	}{
+		{"leading skipped", "\uFEFFa b", []lex.Option{lex.SkipBOM(), lex.RejectBOM()}, []lex.Token{
+			{Typ: tokWord, Pos: 3, Val: "a"},
+			{Typ: tokWord, Pos: 5, Val: "b"},
+			{Typ: lex.TokEOF, Pos: 6},
+		}},
+		{"leading kept", "\uFEFFa b", []lex.Option{lex.RejectBOM()}, []lex.Token{
+			{Typ: tokWord, Pos: 0, Val: "\uFEFFa"},
+			{Typ: tokWord, Pos: 5, Val: "b"},
+			{Typ: lex.TokEOF, Pos: 6},
+		}},
+		{"mid-stream", "a \uFEFFb", []lex.Option{lex.SkipBOM(), lex.RejectBOM()}, []lex.Token{
+			{Typ: tokWord, Pos: 0, Val: "a"},
+			{Typ: lex.TokError, Pos: 2, Val: "unexpected BOM"},
+		}},
+		{"mid-stream allowed", "a \uFEFFb", nil, []lex.Token{
+			{Typ: tokWord, Pos: 0, Val: "a"},
+			{Typ: tokWord, Pos: 2, Val: "\uFEFFb"},
+			{Typ: lex.TokEOF, Pos: 6},
+		}},
+	}
+	for _, tt := range tests {
+		tokens := collect(lex.LexString(tt.input, lexWords, tt.opts...))
+		if diff := lex.DiffTokens(tokens, tt.want, lex.TokenCmpOptions{}); diff != "" {
+			t.Errorf("%s:\n%s", tt.name, diff)
+		}
+	}
+}
+
+func TestWithValueHash(t *testing.T) {
+	tokens := collect(lex.LexString("a b a", lexWords, lex.WithValueHash(nil)))
+	if tokens[0].Hash == 0 || tokens[0].Hash != tokens[2].Hash {
+		t.Errorf("identical values have hashes %x and %x", tokens[0].Hash, tokens[2].Hash)
+	}
+	if tokens[0].Hash == tokens[1].Hash {
+		t.Errorf("different values have the same hash %x", tokens[0].Hash)
+	}
+	if want := uint64(0xaf63dc4c8601ec8c); tokens[0].Hash != want {
+		t.Errorf("FNV-1a of %q = %x, want %x", tokens[0].Val, tokens[0].Hash, want)
+	}
+
+	length := func(s string) uint64 { return uint64(len(s)) }
+	tokens = collect(lex.LexString("abc", lexWords, lex.WithValueHash(length)))
+	if tokens[0].Hash != 3 {
+		t.Errorf("custom hash = %d, want 3", tokens[0].Hash)
+	}
+
+	tokens = collect(lex.LexString("abc", lexWords))
+	if tokens[0].Hash != 0 {
+		t.Errorf("hash without the option = %x, want 0", tokens[0].Hash)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	// split text at each brace, which turns out to be literal unless doubled
+	var lexText lex.StateFn
+	lexText = func(l *lex.Lexer) lex.StateFn {
+		switch {
+		case l.Peek() == lex.EOFRune:
+			return lex.EOF
+		case l.Accept('{'):
+			if l.Accept('{') {
+				l.AcceptUntil('}')
+				l.Accept('}')
+				l.Accept('}')
+				l.Emit(tokAction)
+				return lexText
+			}
+		}
+		l.AcceptUntil('{')
+		l.Emit(tokText)
+		return lexText
+	}
+	input := "a {b} c{{x}}d {e"
+	want := []lex.Token{
+		{Typ: tokText, Pos: 0, Val: "a "},
+		{Typ: tokText, Pos: 2, Val: "{b} c"},
+		{Typ: tokAction, Pos: 7, Val: "{{x}}"},
+		{Typ: tokText, Pos: 12, Val: "d "},
+		{Typ: tokText, Pos: 14, Val: "{e"},
+		{Typ: lex.TokEOF, Pos: 16},
+	}
+	tokens := collect(lex.LexString(input, lexText))
+	if len(tokens) != len(want) {
+		t.Fatalf("got %v, want %v", tokens, want)
+	}
+
+	want = []lex.Token{
+		{Typ: tokText, Pos: 0, Val: "a {b} c"},
+		{Typ: tokAction, Pos: 7, Val: "{{x}}"},
+		{Typ: tokText, Pos: 12, Val: "d {e"},
+		{Typ: lex.TokEOF, Pos: 16},
+	}
+	for _, l := range []*lex.Lexer{
+		lex.LexString(input, lexText, lex.Coalesce(tokText)),
+		lex.LexSync(input, lexText, lex.Coalesce(tokText)),
+	} {
+		tokens = collect(l)
+		if diff := lex.DiffTokens(tokens, want, lex.TokenCmpOptions{}); diff != "" {
+			t.Error(diff)
+		}
+	}
+
+	tokens = collect(lex.LexString("a {b", lexText, lex.Coalesce(tokText, lex.TokEOF)))
+	if len(tokens) != 2 || tokens[0].Val != "a {b" || tokens[1].Typ != lex.TokEOF {
+		t.Errorf("got %v, want text held till the end", tokens)
+	}
+}
+
+func TestYieldEvery(t *testing.T) {
+	input := strings.Repeat("w ", 9) // 9 words and EOF
+	for _, tt := range []struct {
+		n    int
+		want int
+	}{{1, 10}, {3, 3}, {5, 2}, {11, 0}} {
+		yields := 0
+		collect(lex.LexSync(input, lexWords, lex.YieldEvery(tt.n, func() { yields++ })))
+		if yields != tt.want {
+			t.Errorf("YieldEvery(%d): %d yields, want %d", tt.n, yields, tt.want)
+		}
+	}
+}
+
+func TestBOFToken(t *testing.T) {
+	for _, sync := range []bool{false, true} {
+		lexer := lex.LexString
+		if sync {
+			lexer = lex.LexSync
+		}
+		tokens := collect(lexer("a b", lexWords, lex.BOFToken(tokKeyword)))
+		want := []lex.Token{{Typ: tokKeyword}, {Typ: tokWord, Val: "a"}, {Typ: tokWord, Pos: 2, Val: "b"}, {Typ: lex.TokEOF, Pos: 3}}
+		if diff := lex.DiffTokens(tokens, want, lex.TokenCmpOptions{}); diff != "" {
+			t.Errorf("sync %v:\n%s", sync, diff)
+		}
+	}
+	if tokens := collect(lex.LexString("a", lexWords)); tokens[0].Typ != tokWord {
+		t.Errorf("first token without BOFToken = %v, want word", tokens[0])
+	}
+}
+
+func TestPreciseUntil(t *testing.T) {
+	input := "one two three four five"
+	tokens := collect(lex.LexString(input, lexWords, lex.PreciseUntil(6, lex.CoarseText(tokText))))
+	want := []lex.Token{
+		{Typ: tokWord, Pos: 0, Val: "one"},
+		{Typ: tokWord, Pos: 4, Val: "two"},
+		{Typ: tokText, Pos: 7, Val: " three four five"},
+		{Typ: lex.TokEOF, Pos: 23},
+	}
+	if diff := lex.DiffTokens(tokens, want, lex.TokenCmpOptions{}); diff != "" {
+		t.Error(diff)
+	}
+	if tokens := collect(lex.LexString(input, lexWords, lex.PreciseUntil(100, lex.CoarseText(tokText)))); len(tokens) != 6 {
+		t.Errorf("got %d tokens below the limit, want 6", len(tokens))
+	}
+}
+
+func TestOnProgress(t *testing.T) {
+	input := strings.Repeat("word word\n", 10)
+	var calls []lex.Pos
+	collect(lex.LexString(input, lexFields, lex.OnProgress(25, func(pos lex.Pos, line int) {
+		if want := strings.Count(input[:pos], "\n") + 1; line != want {
+			t.Errorf("progress at %d: line %d, want %d", pos, line, want)
+		}
+		calls = append(calls, pos)
+	})))
+	want := []lex.Pos{25, 50, 75, 100}
+	if len(calls) != len(want) {
+		t.Fatalf("got progress at %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d at %d, want %d", i, calls[i], want[i])
+		}
+	}
+	calls = nil
+	collect(lex.LexString(input, lexFields, lex.BaseOffset(1000), lex.OnProgress(50, func(pos lex.Pos, _ int) {
+		calls = append(calls, pos)
+	})))
+	if len(calls) != 2 || calls[0] != 1050 || calls[1] != 1100 {
+		t.Errorf("got progress at %v with base offset, want [1050 1100]", calls)
+	}
+}
+
+func TestMaxStepsPerToken(t *testing.T) {
+	// spin never emits nor consumes input
+	var spin lex.StateFn
+	spin = func(l *lex.Lexer) lex.StateFn {
+		l.Peek()
+		return spin
+	}
+	tokens := collect(lex.LexSync("abc", spin, lex.MaxStepsPerToken(100)))
+	if len(tokens) != 1 || tokens[0].Typ != lex.TokError || tokens[0].Val != "token scan budget exceeded" {
+		t.Errorf("got %v, want budget error", tokens)
+	}
+	// loop never sees 'z', nor checks for the end of the input
+	loop := func(l *lex.Lexer) lex.StateFn {
+		for l.Peek() != 'z' {
+		}
+		return lex.EOF
+	}
+	for _, lexer := range []func(string, lex.StateFn, ...lex.Option) *lex.Lexer{lex.LexString, lex.LexSync} {
+		tokens := collect(lexer("abc", loop, lex.MaxStepsPerToken(10)))
+		if len(tokens) != 1 || tokens[0].Typ != lex.TokError || tokens[0].Val != "token scan budget exceeded" {
+			t.Errorf("got %v, want budget error", tokens)
+		}
+	}
+	long := strings.Repeat("word ", 1000)
+	tokens = collect(lex.LexSync(long, lexWords, lex.MaxStepsPerToken(10)))
+	if last := tokens[len(tokens)-1]; len(tokens) != 1001 || last.Typ != lex.TokEOF {
+		t.Errorf("got %d tokens ending with %v, want 1001 ending with EOF", len(tokens), last)
+	}
+}
@@ -0,0 +1,100 @@
+package lex
+
+import (
+	"strings"
+	"sync"
+)
+
+// SplitLines splits the input into at most n chunks of similar size,
+// each ending with a newline except the last one. Chunks are never empty,
+// except the single chunk of an empty input
+func SplitLines(input string, n int) []string {
+	if n < 1 {
+		n = 1
+	}
+	chunks := make([]string, 0, n)
+	begin := 0
+	for i := 1; i < n && begin < len(input); i++ {
+		target := len(input)*i/n - 1 // a newline right before the target ends the chunk
+		if target < begin {
+			target = begin
+		}
+		nl := strings.IndexByte(input[target:], '\n')
+		if nl < 0 {
+			break
+		}
+		end := target + nl + 1
+		if end == len(input) {
+			break
+		}
+		chunks = append(chunks, input[begin:end])
+		begin = end
+	}
+	return append(chunks, input[begin:])
+}
+
+// LexParallel scans the input split by SplitLines in up to workers goroutines
+// and returns all the emitted tokens as LexAll would. It is only valid for
+// grammars whose tokens and states do not span lines, such as line-oriented logs:
+// every chunk is scanned from the state. An error token ends the result.
+// Options mapping positions, such as BaseOffset and Normalize, options on values
+// and limits apply to every chunk, so an Intern interner must be safe for concurrent
+// use; BOFToken and SkipBOM apply to the first chunk only. Options with per-scan
+// state or callbacks, RecordRunes, OnProgress and YieldEvery, and those acting
+// across lines, ExpandTabs, PreciseUntil, Coalesce and MaxInputBytes below
+// the input size, make LexParallel scan the input in a single goroutine instead
+func LexParallel(input string, state StateFn, workers int, opts ...Option) []Token {
+	chunks := SplitLines(input, workers)
+	if len(chunks) == 1 || !newLexer("", state, opts).splittable(chunks) {
+		return appendTokens(nil, LexSync(input, state, opts...))
+	}
+	results := make([][]Token, len(chunks))
+	var wg sync.WaitGroup
+	offset := 0
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk string, offset Pos) {
+			defer wg.Done()
+			chunkOpts := opts
+			if i > 0 {
+				chunkOpts = append(opts[:len(opts):len(opts)], func(l *Lexer) {
+					l.baseOffset += offset
+					l.bof, l.skipBOM = 0, false
+				})
+			}
+			results[i] = appendTokens(nil, LexSync(chunk, state, chunkOpts...))
+		}(i, chunk, Pos(offset))
+		offset += len(chunk)
+	}
+	wg.Wait()
+	var tokens []Token
+	for i, chunk := range results {
+		for _, tok := range chunk {
+			if tok.Typ == TokEOF && i < len(results)-1 {
+				continue
+			}
+			tokens = append(tokens, tok)
+			if tok.Typ == TokError {
+				return tokens
+			}
+		}
+	}
+	return tokens
+}
+
+// splittable reports whether scanning the chunks separately with the options
+// of l returns the tokens of scanning them at once
+func (l *Lexer) splittable(chunks []string) bool {
+	if l.runeLog != nil || l.progress != nil || l.yield != nil ||
+		l.tabWidth > 0 || l.coarse != nil || len(l.coalesce) > 0 {
+		return false
+	}
+	size := 0
+	for i, chunk := range chunks {
+		if i > 0 && l.rejectBOM && strings.HasPrefix(chunk, bom) {
+			return false // a BOM rejected past the beginning of the input
+		}
+		size += len(chunk)
+	}
+	return l.maxInputBytes == 0 || int(l.maxInputBytes) >= size
+}
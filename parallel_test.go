@@ -0,0 +1,130 @@
+package lex_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/redsift/lex"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		input string
+		n     int
+		want  []string
+	}{
+		{"a\nb\nc\nd\n", 2, []string{"a\nb\n", "c\nd\n"}},
+		{"a\nb\nc\nd", 4, []string{"a\n", "b\n", "c\n", "d"}},
+		{"long line\nb\n", 4, []string{"long line\n", "b\n"}},
+		{"no newline", 3, []string{"no newline"}},
+		{"", 3, []string{""}},
+		{"a\nb", 0, []string{"a\nb"}},
+	}
+	for _, tt := range tests {
+		got := lex.SplitLines(tt.input, tt.n)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("SplitLines(%q, %d) = %q, want %q", tt.input, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestLexParallel(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		b.WriteString("2026-10-14 INFO  request served\n")
+		if i%7 == 0 {
+			b.WriteString("\n\t warn  retry\n")
+		}
+	}
+	inputs := []string{b.String(), strings.TrimSuffix(b.String(), "\n"), "single line", ""}
+	for _, input := range inputs {
+		want := lex.LexAll(input, lexFields)
+		for _, workers := range []int{1, 2, 3, 8, 64} {
+			got := lex.LexParallel(input, lexFields, workers)
+			if diff := lex.DiffTokens(got, want, lex.TokenCmpOptions{}); diff != "" {
+				t.Errorf("%d workers over %d bytes:\n%s", workers, len(input), diff)
+			}
+		}
+	}
+}
+
+func TestLexParallelOptions(t *testing.T) {
+	input := "alpha beta\ngamma\n\u00e9t\u00e9 delta\n"
+	opts := []lex.Option{lex.BaseOffset(100), lex.CopyValues(), lex.WithValueHash(nil)}
+	want := lex.LexAll(input, lexFields, opts...)
+	got := lex.LexParallel(input, lexFields, 3, opts...)
+	if diff := lex.DiffTokens(got, want, lex.TokenCmpOptions{}); diff != "" {
+		t.Error(diff)
+	}
+	if got[0].Pos != 100 || got[0].Hash == 0 {
+		t.Errorf("got %#v, want the options applied", got[0])
+	}
+	got = lex.LexParallel(input, lexFields, 3, lex.MaxRune(unicode.MaxASCII))
+	if last := got[len(got)-1]; last.Typ != lex.TokError || last.Pos != 17 {
+		t.Errorf("got %v last, want an error at 17", last)
+	}
+}
+
+func TestLexParallelStatefulOptions(t *testing.T) {
+	input := strings.Repeat("a\tb cd\n", 8)
+	tests := []struct {
+		name string
+		opts func() []lex.Option
+	}{
+		{"bof", func() []lex.Option { return []lex.Option{lex.BOFToken(tokKeyword)} }},
+		{"skip bom", func() []lex.Option { return []lex.Option{lex.SkipBOM(), lex.BOFToken(tokKeyword)} }},
+		{"expand tabs", func() []lex.Option { return []lex.Option{lex.ExpandTabs(8)} }},
+		{"precise until", func() []lex.Option { return []lex.Option{lex.PreciseUntil(20, lex.CoarseText(tokText))} }},
+		{"coalesce", func() []lex.Option { return []lex.Option{lex.Coalesce(tokWord)} }},
+		{"max input bytes", func() []lex.Option { return []lex.Option{lex.MaxInputBytes(30)} }},
+		{"callbacks", func() []lex.Option {
+			var log lex.RuneLog
+			calls := 0
+			count := func(lex.Pos, int) { calls++ }
+			return []lex.Option{lex.RecordRunes(&log), lex.OnProgress(5, count), lex.YieldEvery(2, func() { calls++ })}
+		}},
+	}
+	for _, tt := range tests {
+		for _, input := range []string{input, "\uFEFF" + input} {
+			want := lex.LexAll(input, lexFields, tt.opts()...)
+			got := lex.LexParallel(input, lexFields, 4, tt.opts()...)
+			if diff := lex.DiffTokens(got, want, lex.TokenCmpOptions{}); diff != "" {
+				t.Errorf("%s over %q:\n%s", tt.name, input[:3], diff)
+			}
+		}
+	}
+
+	var seqLog, parLog lex.RuneLog
+	lex.LexAll(input, lexFields, lex.RecordRunes(&seqLog))
+	lex.LexParallel(input, lexFields, 4, lex.RecordRunes(&parLog))
+	if len(parLog) != len(seqLog) {
+		t.Errorf("recorded %d runes, want %d", len(parLog), len(seqLog))
+	}
+}
+
+func TestLexParallelError(t *testing.T) {
+	input := "a\nb\n!\nc\n"
+	want := lex.LexAll(input, lexIdents)
+	got := lex.LexParallel(input, lexIdents, 4)
+	if diff := lex.DiffTokens(got, want, lex.TokenCmpOptions{}); diff != "" {
+		t.Error(diff)
+	}
+	if last := got[len(got)-1]; last.Typ != lex.TokError || last.Pos != 4 {
+		t.Errorf("got %v last, want an error at 4", last)
+	}
+}
+
+// lexIdents scans lines of letters
+func lexIdents(l *lex.Lexer) lex.StateFn {
+	l.IgnoreRunes(func(r rune) bool { return r == '\n' })
+	switch r := l.Peek(); {
+	case r == lex.EOFRune:
+		return lex.EOF
+	case r < 'a' || r > 'z':
+		return l.Errorf("unexpected %q", r)
+	}
+	l.AcceptUntil('\n')
+	l.Emit(tokIdent)
+	return lexIdents
+}
@@ -0,0 +1,62 @@
+package lex
+
+// QuoteStyle is the way a string literal is quoted in the input
+type QuoteStyle int
+
+const (
+	// QuoteNone is for tokens other than string literals
+	QuoteNone QuoteStyle = iota
+	// QuoteSingle is a 'literal' with backslash escapes
+	QuoteSingle
+	// QuoteDouble is a "literal" with backslash escapes
+	QuoteDouble
+	// QuoteBacktick is a raw `literal` without escapes
+	QuoteBacktick
+	// QuoteTripleSingle is a '''literal''' with backslash escapes spanning lines
+	QuoteTripleSingle
+	// QuoteTripleDouble is a """literal""" with backslash escapes spanning lines
+	QuoteTripleDouble
+)
+
+var quoteDelims = []struct {
+	delim string
+	style QuoteStyle
+}{
+	{`"""`, QuoteTripleDouble},
+	{`'''`, QuoteTripleSingle},
+	{`"`, QuoteDouble},
+	{`'`, QuoteSingle},
+	{"`", QuoteBacktick},
+}
+
+// ScanString consumes a string literal in any of the quote styles and emits it
+// as a token of type t recording the style in its Quote.
+// Backslash escapes the next rune in all the styles but backtick.
+// It returns false when the lexer is not at a quote, consuming nothing,
+// or when the literal is unterminated, consuming the rest of the input.
+// With the UnterminatedEmitFlagged policy an unterminated literal is emitted
+// flagged Unterminated, with its style, and ScanString returns true
+func ScanString(l *Lexer, t TokenType) bool {
+	for _, q := range quoteDelims {
+		if !l.AcceptString(q.delim) {
+			continue
+		}
+		for !l.AcceptString(q.delim) {
+			switch l.Next() {
+			case eof:
+				if l.unterminated == UnterminatedEmitFlagged {
+					l.emit(Token{Typ: t, Info: &TokenInfo{Quote: q.style, Unterminated: true}})
+					return true
+				}
+				return false
+			case '\\':
+				if q.style != QuoteBacktick {
+					l.Next()
+				}
+			}
+		}
+		l.emit(Token{Typ: t, Info: &TokenInfo{Quote: q.style}})
+		return true
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestScanString(t *testing.T) {
+	tests := []struct {
+		input string
+		ok    bool
+		val   string
+		style lex.QuoteStyle
+	}{
+		{`"double" x`, true, `"double"`, lex.QuoteDouble},
+		{`'single'`, true, `'single'`, lex.QuoteSingle},
+		{`'it\'s'`, true, `'it\'s'`, lex.QuoteSingle},
+		{"`raw\\`", true, "`raw\\`", lex.QuoteBacktick},
+		{`"""doc "quoted" ""\"" """`, true, `"""doc "quoted" ""\"" """`, lex.QuoteTripleDouble},
+		{"'''multi\nline'''", true, "'''multi\nline'''", lex.QuoteTripleSingle},
+		{`""`, true, `""`, lex.QuoteDouble},
+		{`"open`, false, "", lex.QuoteNone},
+		{`x`, false, "", lex.QuoteNone},
+	}
+	for _, tt := range tests {
+		var ok bool
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			ok = lex.ScanString(l, tokWord)
+			return lex.EOF
+		}))
+		if ok != tt.ok {
+			t.Errorf("%s: ScanString() = %v, want %v", tt.input, ok, tt.ok)
+		}
+		if !tt.ok {
+			continue
+		}
+		if tokens[0].Val != tt.val || tokens[0].Quote() != tt.style {
+			t.Errorf("%s: got %q quoted with %d, want %q quoted with %d", tt.input, tokens[0].Val, tokens[0].Quote(), tt.val, tt.style)
+		}
+	}
+
+	tokens := collect(lex.LexString("word", lexWords))
+	if tokens[0].Quote() != lex.QuoteNone {
+		t.Errorf("word has quote style %d", tokens[0].Quote())
+	}
+}
+
+// lexStrings emits string literals ignoring spaces between them
+func lexStrings(l *lex.Lexer) lex.StateFn {
+	l.IgnoreRunes(func(r rune) bool { return r == ' ' })
+	if l.Peek() == lex.EOFRune {
+		return lex.EOF
+	}
+	if !lex.ScanString(l, tokWord) {
+		return l.EmitUnterminated(tokWord, "unterminated string")
+	}
+	return lexStrings
+}
+
+func TestUnterminatedPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []lex.Option
+		want []lex.Token
+	}{
+		{"default", nil, []lex.Token{
+			{Typ: tokWord, Pos: 0, Val: `"a"`, Info: &lex.TokenInfo{Quote: lex.QuoteDouble}},
+			{Typ: lex.TokError, Pos: 4, Val: "unterminated string"},
+		}},
+		{"error", []lex.Option{lex.OnUnterminated(lex.UnterminatedError)}, []lex.Token{
+			{Typ: tokWord, Pos: 0, Val: `"a"`, Info: &lex.TokenInfo{Quote: lex.QuoteDouble}},
+			{Typ: lex.TokError, Pos: 4, Val: "unterminated string"},
+		}},
+		{"flagged", []lex.Option{lex.OnUnterminated(lex.UnterminatedEmitFlagged)}, []lex.Token{
+			{Typ: tokWord, Pos: 0, Val: `"a"`, Info: &lex.TokenInfo{Quote: lex.QuoteDouble}},
+			{Typ: tokWord, Pos: 4, Val: `"bc`, Info: &lex.TokenInfo{Quote: lex.QuoteDouble, Unterminated: true}},
+			{Typ: lex.TokEOF, Pos: 7},
+		}},
+	}
+	for _, tt := range tests {
+		tokens := collect(lex.LexString(`"a" "bc`, lexStrings, tt.opts...))
+		if diff := lex.DiffTokens(tokens, tt.want, lex.TokenCmpOptions{}); diff != "" {
+			t.Errorf("%s:\n%s", tt.name, diff)
+		}
+	}
+	tokens := collect(lex.LexString("'''open", lexStrings, lex.OnUnterminated(lex.UnterminatedEmitFlagged)))
+	if tokens[0].Quote() != lex.QuoteTripleSingle || !tokens[0].Unterminated() {
+		t.Errorf("got %#v, want an unterminated triple-single-quoted literal", tokens[0])
+	}
+}
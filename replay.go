@@ -0,0 +1,37 @@
+package lex
+
+// RuneRecord is a rune returned by Next and the position it was read at
+type RuneRecord struct {
+	Pos  Pos    // The position, in bytes, of the rune in the scanned input
+	Rune rune   // The rune, EOFRune at the end of the input
+	Raw  string // The bytes the rune was decoded from, which differ for invalid UTF-8
+}
+
+// RuneLog is the sequence of runes returned by Next, in order
+type RuneLog []RuneRecord
+
+// RecordRunes appends every rune returned by Next, including EOFRune, to log,
+// preceded by the byte order mark skipped with SkipBOM
+func RecordRunes(log *RuneLog) Option {
+	return func(l *Lexer) {
+		l.runeLog = log
+	}
+}
+
+// ReplaySource reconstructs the input read by a recorded scan, from the position
+// of its first record, for lexing it again with the same options.
+// Runes read more than once, after Backup, appear once. Input the scan never read
+// is not reproduced, so options transforming the input should be left out of the replay
+func ReplaySource(log RuneLog) string {
+	if len(log) == 0 {
+		return ""
+	}
+	var b []byte
+	begin := log[0].Pos
+	for _, rec := range log {
+		if rec.Pos == begin+Pos(len(b)) {
+			b = append(b, rec.Raw...)
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,48 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestRecordRunes(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  []lex.Option
+	}{
+		{"func f() {\n\tr\u00e9sum\u00e9 := x[i] + 1\n}\n", nil},
+		{"a\xffb cd", nil},
+		{"\uFEFFa b", []lex.Option{lex.SkipBOM()}},
+	}
+	for _, tt := range tests {
+		input := tt.input
+		var log lex.RuneLog
+		recorded := collect(lex.LexString(input, lexFields, append(tt.opts, lex.RecordRunes(&log))...))
+		if len(log) == 0 || log[len(log)-1].Rune != lex.EOFRune {
+			t.Fatalf("log does not end with EOF: %v", log)
+		}
+		source := lex.ReplaySource(log)
+		if source != input {
+			t.Errorf("ReplaySource() = %q, want %q", source, input)
+		}
+		var replayLog lex.RuneLog
+		replayed := collect(lex.LexString(source, lexFields, append(tt.opts, lex.RecordRunes(&replayLog))...))
+		if diff := lex.DiffTokens(recorded, replayed, lex.TokenCmpOptions{}); diff != "" {
+			t.Errorf("replayed tokens differ:\n%s", diff)
+		}
+		if len(replayLog) != len(log) {
+			t.Errorf("replay read %d runes, want %d", len(replayLog), len(log))
+		}
+	}
+}
+
+func TestReplaySourceOffset(t *testing.T) {
+	log := lex.RuneLog{{Pos: 3, Rune: 'a', Raw: "a"}, {Pos: 4, Rune: ' ', Raw: " "}, {Pos: 4, Rune: ' ', Raw: " "}, {Pos: 5, Rune: lex.EOFRune}}
+	if got := lex.ReplaySource(log); got != "a " {
+		t.Errorf("ReplaySource() = %q, want %q", got, "a ")
+	}
+	if got := lex.ReplaySource(nil); got != "" {
+		t.Errorf("ReplaySource(nil) = %q", got)
+	}
+}
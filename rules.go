@@ -0,0 +1,81 @@
+package lex
+
+// Rule describes a token of type Type; Match returns the length in bytes
+// of the token at the beginning of the input, 0 when it does not match
+type Rule struct {
+	Type  TokenType
+	Match func(input string) int
+}
+
+// RuleMatch is a candidate for the token at the current position
+type RuleMatch struct {
+	Rule int       // Index of the matching rule
+	Type TokenType // Type of the matching rule
+	Len  int       // Length of the match in bytes
+}
+
+// Resolver picks one of the candidates matching at the same position
+// and returns its index. Candidates are listed in the order of the rules
+type Resolver func(candidates []RuleMatch) int
+
+// FirstMatch resolves conflicts in favor of the rule listed first
+func FirstMatch(candidates []RuleMatch) int {
+	return 0
+}
+
+// LongestMatch resolves conflicts in favor of the longest match,
+// the rule listed first wins among matches of the same length
+func LongestMatch(candidates []RuleMatch) int {
+	best := 0
+	for i, c := range candidates {
+		if c.Len > candidates[best].Len {
+			best = i
+		}
+	}
+	return best
+}
+
+// Rules tokenizes input by the rules
+type Rules struct {
+	Rules    []Rule
+	Resolver Resolver // FirstMatch if nil
+}
+
+// Scan consumes and emits the token matching at the current position.
+// It returns false, consuming nothing, when no rule matches
+func (rs *Rules) Scan(l *Lexer) bool {
+	var candidates []RuleMatch
+	input := l.input[l.pos:]
+	for i, r := range rs.Rules {
+		if n := r.Match(input); n > 0 {
+			candidates = append(candidates, RuleMatch{i, r.Type, n})
+		}
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+	resolve := rs.Resolver
+	if resolve == nil {
+		resolve = FirstMatch
+	}
+	match := candidates[resolve(candidates)]
+	l.advance(l.pos + Pos(match.Len))
+	l.Emit(match.Type)
+	return true
+}
+
+// State returns the state function tokenizing the rest of the input by the rules.
+// Input not matching any of them is reported as an error
+func (rs *Rules) State() StateFn {
+	var state StateFn
+	state = func(l *Lexer) StateFn {
+		if l.Peek() == eof {
+			return EOF
+		}
+		if !rs.Scan(l) {
+			return l.Errorf("unexpected %q", l.Peek())
+		}
+		return state
+	}
+	return state
+}
@@ -0,0 +1,60 @@
+package lex_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+// matchRegexp makes a rule matcher from the regular expression anchored at the beginning of input
+func matchRegexp(expr string) func(string) int {
+	re := regexp.MustCompile(`^(?:` + expr + `)`)
+	return func(input string) int {
+		if loc := re.FindStringIndex(input); loc != nil {
+			return loc[1]
+		}
+		return 0
+	}
+}
+
+func TestRulesResolver(t *testing.T) {
+	rules := []lex.Rule{
+		{Type: tokKeyword, Match: matchRegexp(`if|in`)},
+		{Type: tokIdent, Match: matchRegexp(`[a-z]+`)},
+		{Type: tokSpace, Match: matchRegexp(`\s+`)},
+	}
+	tests := []struct {
+		name     string
+		resolver lex.Resolver
+		want     []lex.TokenType
+	}{
+		{"default", nil, []lex.TokenType{tokKeyword, tokIdent, tokSpace, tokKeyword, lex.TokEOF}},
+		{"first", lex.FirstMatch, []lex.TokenType{tokKeyword, tokIdent, tokSpace, tokKeyword, lex.TokEOF}},
+		{"longest", lex.LongestMatch, []lex.TokenType{tokIdent, tokSpace, tokKeyword, lex.TokEOF}},
+		{"custom", func(candidates []lex.RuleMatch) int { return len(candidates) - 1 }, []lex.TokenType{tokIdent, tokSpace, tokIdent, lex.TokEOF}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := &lex.Rules{Rules: rules, Resolver: tt.resolver}
+			tokens := collect(lex.LexString("input if", rs.State()))
+			if len(tokens) != len(tt.want) {
+				t.Fatalf("got %v, want %d tokens", tokens, len(tt.want))
+			}
+			for i, tok := range tokens {
+				if tok.Typ != tt.want[i] {
+					t.Errorf("token %d %v has type %d, want %d", i, tok, tok.Typ, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRulesNoMatch(t *testing.T) {
+	rs := &lex.Rules{Rules: []lex.Rule{{Type: tokIdent, Match: matchRegexp(`[a-z]+`)}}}
+	tokens := collect(lex.LexString("ab1", rs.State()))
+	last := tokens[len(tokens)-1]
+	if last.Typ != lex.TokError || last.Pos != 2 {
+		t.Errorf("got %v at %d, want error at 2", last, last.Pos)
+	}
+}
@@ -0,0 +1,141 @@
+package lex
+
+import "strings"
+
+// ScanFixedFields emits a token of type t per field of a fixed-width record.
+// Widths are in runes, so multibyte text does not break the layout.
+// A record ends at a newline, which is not consumed, or at the end of the input.
+// It returns false for a short record: the truncated field is still emitted
+// unless it is empty, and the remaining fields are not
+func ScanFixedFields(l *Lexer, widths []int, t TokenType) bool {
+	for _, width := range widths {
+		n := 0
+		for ; n < width; n++ {
+			if r := l.Next(); r == '\n' || r == eof {
+				l.Backup()
+				break
+			}
+		}
+		if n > 0 {
+			l.Emit(t)
+		}
+		if n < width {
+			return false
+		}
+	}
+	return true
+}
+
+// ScanDoubledQuote consumes a literal enclosed in quote runes where the quote
+// itself is escaped by doubling it, as SQL does, and returns the unescaped value.
+// It returns false when the lexer is not at the quote, consuming nothing,
+// or when the literal is unterminated, consuming the rest of the input
+func ScanDoubledQuote(l *Lexer, quote rune) (value string, ok bool) {
+	if !l.Accept(quote) {
+		return "", false
+	}
+	var b strings.Builder
+	for {
+		switch r := l.Next(); {
+		case r == eof:
+			return b.String(), false
+		case r != quote:
+			b.WriteRune(r)
+		case !l.Accept(quote):
+			return b.String(), true
+		default:
+			b.WriteRune(quote)
+		}
+	}
+}
+
+// SpanConfig describes a span of code, such as a block comment,
+// which may contain string literals
+type SpanConfig struct {
+	Open, Close string // Delimiters of the span
+	Quotes      []rune // Runes opening and closing string literals inside the span
+	Escape      rune   // Rune escaping the next one inside string literals, 0 for none
+}
+
+// ScanCodeSpan consumes a span from cfg.Open through cfg.Close. String literals
+// inside the span are skipped as a whole, so Close within them does not end the span,
+// while Open and Close are not recognized within the literals themselves.
+// It returns false when the lexer is not at Open, consuming nothing,
+// or when the span or a literal inside it is unterminated, consuming the rest of the input
+func ScanCodeSpan(l *Lexer, cfg SpanConfig) bool {
+	if !l.AcceptString(cfg.Open) {
+		return false
+	}
+	for !l.AcceptString(cfg.Close) {
+		r := l.Next()
+		switch {
+		case r == eof:
+			return false
+		case indexRune(r, cfg.Quotes...) >= 0:
+			if !skipLiteral(l, r, cfg.Escape) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// skipLiteral consumes the rest of the literal opened with quote
+func skipLiteral(l *Lexer, quote, escape rune) bool {
+	for {
+		switch l.Next() {
+		case eof:
+			return false
+		case quote:
+			return true
+		case escape:
+			if escape != 0 {
+				l.Next()
+			}
+		}
+	}
+}
+
+// ScanWithDynamicDelimiter consumes a section whose first rune chooses the delimiter
+// closing it, as in sed's s#a/b#c#, and returns the delimiter and the body between.
+// It returns false when the lexer is at a newline or the end of the input, consuming
+// nothing, or when the section is unterminated, consuming the rest of the line
+func ScanWithDynamicDelimiter(l *Lexer) (delim rune, body string, ok bool) {
+	delim = l.Next()
+	if delim == '\n' || delim == eof {
+		l.Backup()
+		return 0, "", false
+	}
+	begin := l.pos
+	for {
+		switch r := l.Next(); r {
+		case delim:
+			return delim, l.input[begin : l.pos-l.width], true
+		case '\n', eof:
+			l.Backup()
+			return delim, l.input[begin:l.pos], false
+		}
+	}
+}
+
+// ScanANSIEscape consumes an ANSI control sequence, ESC [ followed by parameter
+// and intermediate bytes and a final letter, such as the SGR sequence "\x1b[1;31m".
+// It returns false, consuming nothing, when the lexer is not at a sequence
+// or the sequence is truncated by an invalid byte or the end of the input
+func ScanANSIEscape(l *Lexer) bool {
+	begin := l.pos
+	if !l.AcceptString("\x1b[") {
+		return false
+	}
+	for {
+		switch r := l.Next(); {
+		case r >= 0x30 && r <= 0x3f: // parameter bytes
+		case r >= 0x20 && r <= 0x2f: // intermediate bytes
+		case r >= 0x40 && r <= 0x7e:
+			return true
+		default:
+			l.pos, l.width = begin, 0
+			return false
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestScanFixedFields(t *testing.T) {
+	const field = tokWord
+	tests := []struct {
+		name  string
+		input string
+		ok    bool
+		want  []string
+	}{
+		{"exact", "AB123Zürich", true, []string{"AB", "123", "Züric"}},
+		{"exact with newline", "AB123Basel\nCD", true, []string{"AB", "123", "Basel"}},
+		{"short last field", "AB123Bern", false, []string{"AB", "123", "Bern"}},
+		{"short record", "AB1\nCD", false, []string{"AB", "1"}},
+		{"missing fields", "AB", false, []string{"AB"}},
+		{"empty", "", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ok bool
+			tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+				ok = lex.ScanFixedFields(l, []int{2, 3, 5}, field)
+				return lex.EOF
+			}))
+			if ok != tt.ok {
+				t.Errorf("ScanFixedFields() = %v, want %v", ok, tt.ok)
+			}
+			var got []string
+			for _, tok := range tokens {
+				if tok.Typ == field {
+					got = append(got, tok.Val)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got fields %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("field %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScanDoubledQuote(t *testing.T) {
+	tests := []struct {
+		input string
+		value string
+		ok    bool
+		raw   string
+	}{
+		{`'it''s' rest`, "it's", true, `'it''s'`},
+		{`''`, "", true, `''`},
+		{`''''`, "'", true, `''''`},
+		{`'a''''b'`, "a''b", true, `'a''''b'`},
+		{`'it''s`, "it's", false, `'it''s`},
+		{`'dangling''`, "dangling'", false, `'dangling''`},
+		{`"other"`, "", false, ``},
+	}
+	for _, tt := range tests {
+		var (
+			value string
+			ok    bool
+		)
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			value, ok = lex.ScanDoubledQuote(l, '\'')
+			l.Emit(tokWord)
+			return lex.EOF
+		}))
+		if value != tt.value || ok != tt.ok {
+			t.Errorf("%s: got (%q, %v), want (%q, %v)", tt.input, value, ok, tt.value, tt.ok)
+		}
+		if tokens[0].Val != tt.raw {
+			t.Errorf("%s: consumed %q, want %q", tt.input, tokens[0].Val, tt.raw)
+		}
+	}
+}
+
+func TestScanCodeSpan(t *testing.T) {
+	comment := lex.SpanConfig{Open: "/*", Close: "*/", Quotes: []rune{'"', '\''}, Escape: '\\'}
+	tests := []struct {
+		input string
+		ok    bool
+		span  string
+	}{
+		{`/* plain */ x`, true, `/* plain */`},
+		{`/* "*/" */ x`, true, `/* "*/" */`},
+		{`/* 'a*/b' "c\"*/" */ x`, true, `/* 'a*/b' "c\"*/" */`},
+		{`/**/`, true, `/**/`},
+		{`/* "*/`, false, `/* "*/`},
+		{`/* open`, false, `/* open`},
+		{`x /* */`, false, ``},
+	}
+	for _, tt := range tests {
+		var ok bool
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			ok = lex.ScanCodeSpan(l, comment)
+			l.Emit(tokWord)
+			return lex.EOF
+		}))
+		if ok != tt.ok || tokens[0].Val != tt.span {
+			t.Errorf("%s: got (%q, %v), want (%q, %v)", tt.input, tokens[0].Val, ok, tt.span, tt.ok)
+		}
+	}
+}
+
+func TestScanWithDynamicDelimiter(t *testing.T) {
+	tests := []struct {
+		input string
+		delim rune
+		body  string
+		ok    bool
+		raw   string
+	}{
+		{"/usr/bin/ rest", '/', "usr", true, "/usr/"},
+		{"#/usr/bin# rest", '#', "/usr/bin", true, "#/usr/bin#"},
+		{"##", '#', "", true, "##"},
+		{"#open\nnext#", '#', "open", false, "#open"},
+		{"/open", '/', "open", false, "/open"},
+		{"\n/x/", 0, "", false, ""},
+		{"", 0, "", false, ""},
+	}
+	for _, tt := range tests {
+		var (
+			delim rune
+			body  string
+			ok    bool
+		)
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			delim, body, ok = lex.ScanWithDynamicDelimiter(l)
+			l.Emit(tokWord)
+			return lex.EOF
+		}))
+		if delim != tt.delim || body != tt.body || ok != tt.ok {
+			t.Errorf("%q: got (%q, %q, %v), want (%q, %q, %v)", tt.input, delim, body, ok, tt.delim, tt.body, tt.ok)
+		}
+		if tokens[0].Val != tt.raw {
+			t.Errorf("%q: consumed %q, want %q", tt.input, tokens[0].Val, tt.raw)
+		}
+	}
+}
+
+func TestScanWithDynamicDelimiterSubstitution(t *testing.T) {
+	// s#a/b#c#: the pattern's delimiter also closes the replacement
+	var pattern, replacement string
+	collect(lex.LexString("s#a/b#c#", func(l *lex.Lexer) lex.StateFn {
+		l.Accept('s')
+		var delim rune
+		delim, pattern, _ = lex.ScanWithDynamicDelimiter(l)
+		l.Ignore()
+		l.AcceptUntil(delim)
+		replacement = l.Current()
+		return lex.EOF
+	}))
+	if pattern != "a/b" || replacement != "c" {
+		t.Errorf("got pattern %q replacement %q, want %q and %q", pattern, replacement, "a/b", "c")
+	}
+}
+
+func TestScanANSIEscape(t *testing.T) {
+	// lexTerminal separates text from control sequences
+	lexTerminal := func(l *lex.Lexer) lex.StateFn {
+		for {
+			if lex.ScanANSIEscape(l) {
+				l.Emit(tokPunct)
+				continue
+			}
+			if l.Next() == lex.EOFRune {
+				return lex.EOF
+			}
+			l.Emit(tokText)
+		}
+	}
+	tests := []struct {
+		input string
+		want  []lex.Token
+	}{
+		{"\x1b[1;31mok\x1b[0m", []lex.Token{
+			{Typ: tokPunct, Pos: 0, Val: "\x1b[1;31m"},
+			{Typ: tokText, Pos: 7, Val: "o"},
+			{Typ: tokText, Pos: 8, Val: "k"},
+			{Typ: tokPunct, Pos: 9, Val: "\x1b[0m"},
+			{Typ: lex.TokEOF, Pos: 13},
+		}},
+		{"a\x1b[3", []lex.Token{
+			{Typ: tokText, Pos: 0, Val: "a"},
+			{Typ: tokText, Pos: 1, Val: "\x1b"},
+			{Typ: tokText, Pos: 2, Val: "["},
+			{Typ: tokText, Pos: 3, Val: "3"},
+			{Typ: lex.TokEOF, Pos: 4},
+		}},
+		{"\x1b[2\x1b[K", []lex.Token{
+			{Typ: tokText, Pos: 0, Val: "\x1b"},
+			{Typ: tokText, Pos: 1, Val: "["},
+			{Typ: tokText, Pos: 2, Val: "2"},
+			{Typ: tokPunct, Pos: 3, Val: "\x1b[K"},
+			{Typ: lex.TokEOF, Pos: 6},
+		}},
+	}
+	for _, tt := range tests {
+		tokens := collect(lex.LexString(tt.input, lexTerminal))
+		if diff := lex.DiffTokens(tokens, tt.want, lex.TokenCmpOptions{}); diff != "" {
+			t.Errorf("%q:\n%s", tt.input, diff)
+		}
+	}
+}
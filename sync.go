@@ -0,0 +1,39 @@
+package lex
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// LexSync creates a new *Lexer that will scan given input starting from the state
+// without a lexing goroutine: state functions are executed by NextToken on demand
+// in the caller's goroutine
+func LexSync(input string, state StateFn, opts ...Option) *Lexer {
+	return newLexer(input, state, opts)
+}
+
+// Step executes exactly one state function of a lexer created by LexSync and
+// returns the name of the state and the tokens it emitted, which are not
+// returned by NextToken afterwards. It returns an empty name once the scan has finished
+func (l *Lexer) Step() (stateName string, emitted []Token) {
+	if l.tokens != nil {
+		panic("lex: Step called for a lexer not created by LexSync")
+	}
+	if l.state == nil {
+		return "", nil
+	}
+	stateName = nameOf(l.state)
+	pending := len(l.queue)
+	l.step()
+	emitted = append(emitted, l.queue[pending:]...)
+	l.queue = l.queue[:pending]
+	return stateName, emitted
+}
+
+// nameOf returns the name of the state function without its package path,
+// e.g. "lex.EOF"
+func nameOf(state StateFn) string {
+	name := runtime.FuncForPC(reflect.ValueOf(state).Pointer()).Name()
+	return name[strings.LastIndexByte(name, '/')+1:]
+}
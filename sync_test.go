@@ -0,0 +1,73 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestLexSync(t *testing.T) {
+	input := "one two three"
+	async := lex.LexAll(input, lexWords)
+	l := lex.LexSync(input, lexWords)
+	for i, want := range async {
+		if got := l.NextToken(); got != want {
+			t.Errorf("token %d = %#v, want %#v", i, got, want)
+		}
+	}
+	if got := l.NextToken(); got != (lex.Token{}) {
+		t.Errorf("got %#v after the scan, want zero token", got)
+	}
+	if got := collect(lex.LexSync(input, lexWords, lex.MaxInputBytes(5))); len(got) != 2 || got[1].Typ != lex.TokError {
+		t.Errorf("got %v, want a word and an error", got)
+	}
+}
+
+func TestStep(t *testing.T) {
+	var lexSpace lex.StateFn
+	lexWord := func(l *lex.Lexer) lex.StateFn {
+		if l.AcceptUntil(' ') {
+			l.Emit(tokWord)
+		}
+		return lexSpace
+	}
+	lexSpace = func(l *lex.Lexer) lex.StateFn {
+		if l.Peek() == lex.EOFRune {
+			return lex.EOF
+		}
+		l.AcceptRun(' ')
+		l.Emit(tokSpace)
+		return lexWord
+	}
+
+	l := lex.LexSync("ab cd", lexWord)
+	want := []struct {
+		state string
+		vals  []string
+	}{
+		{"lex_test.TestStep.func1", []string{"ab"}},
+		{"lex_test.TestStep.func2", []string{" "}},
+		{"lex_test.TestStep.func1", []string{"cd"}},
+		{"lex_test.TestStep.func2", nil},
+		{"lex.EOF", []string{""}},
+		{"", nil},
+	}
+	for i, w := range want {
+		state, emitted := l.Step()
+		if state != w.state {
+			t.Errorf("step %d ran %q, want %q", i, state, w.state)
+		}
+		if len(emitted) != len(w.vals) {
+			t.Errorf("step %d emitted %v, want %q", i, emitted, w.vals)
+			continue
+		}
+		for j := range emitted {
+			if emitted[j].Val != w.vals[j] {
+				t.Errorf("step %d emitted %q, want %q", i, emitted[j].Val, w.vals[j])
+			}
+		}
+	}
+	if got := l.NextToken(); got != (lex.Token{}) {
+		t.Errorf("NextToken() returned stepped token %#v", got)
+	}
+}
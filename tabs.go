@@ -0,0 +1,67 @@
+package lex
+
+import "strings"
+
+// ExpandTabs makes the lexer scan the input with tabs expanded to spaces up to
+// the next tab stop every width columns. Positions of emitted tokens refer to
+// the expanded input; OriginalPos maps them back to the input given to LexString
+func ExpandTabs(width int) Option {
+	return func(l *Lexer) {
+		l.tabWidth = width
+	}
+}
+
+// expandTabs expands tabs of input tracking where the offsets change
+func expandTabs(input string, width int) (string, []posMapping) {
+	if width <= 0 || !strings.Contains(input, "\t") {
+		return input, nil
+	}
+	var (
+		b       strings.Builder
+		mapping = []posMapping{{0, 0}}
+		column  int
+	)
+	for i, r := range input {
+		switch r {
+		case '\t':
+			mapping = append(mapping, posMapping{Pos(b.Len()), Pos(i)})
+			n := width - column%width
+			b.WriteString(strings.Repeat(" ", n))
+			column += n
+			mapping = append(mapping, posMapping{Pos(b.Len()), Pos(i + 1)})
+			continue
+		case '\n':
+			column = 0
+		default:
+			column++
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), mapping
+}
+
+// OriginalPos maps the position of an emitted token to the offset
+// in the input given to LexString when tabs are expanded.
+// A position within the spaces of a tab maps to the tab itself,
+// one before the BaseOffset maps to the start of the input
+func (l *Lexer) OriginalPos(pos Pos) Pos {
+	if l.tabMap == nil {
+		return pos
+	}
+	if pos < l.baseOffset {
+		return l.baseOffset
+	}
+	return originalPos(l.tabMap, pos-l.baseOffset) + l.baseOffset
+}
+
+// sourcePos maps a position in the scanned input to the offset
+// in the input given to LexString, undoing Normalize and ExpandTabs
+func (l *Lexer) sourcePos(pos Pos) Pos {
+	if l.normMap != nil {
+		pos = originalPos(l.normMap, pos)
+	}
+	if l.tabMap != nil {
+		pos = originalPos(l.tabMap, pos)
+	}
+	return pos
+}
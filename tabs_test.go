@@ -0,0 +1,71 @@
+package lex_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/redsift/lex"
+)
+
+// lexFields emits runs of non-space runes as tokens
+func lexFields(l *lex.Lexer) lex.StateFn {
+	l.IgnoreRunes(unicode.IsSpace)
+	if l.CountRunFunc(func(r rune) bool { return !unicode.IsSpace(r) }) == 0 {
+		return lex.EOF
+	}
+	l.Emit(tokWord)
+	return lexFields
+}
+
+func TestExpandTabs(t *testing.T) {
+	input := "\tif x\n\t\tbar\nab\tc"
+	l := lex.LexString(input, lexFields, lex.ExpandTabs(4))
+	tokens := collect(l)
+	want := []struct {
+		val      string
+		pos      lex.Pos
+		original lex.Pos
+	}{
+		{"if", 4, 1},
+		{"x", 7, 4},
+		{"bar", 17, 8},
+		{"ab", 21, 12},
+		{"c", 25, 15},
+		{"", 26, 16},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %v, want %d tokens", tokens, len(want))
+	}
+	for i, w := range want {
+		if tokens[i].Val != w.val || tokens[i].Pos != w.pos {
+			t.Errorf("token %d = %q at %d, want %q at %d", i, tokens[i].Val, tokens[i].Pos, w.val, w.pos)
+		}
+		if got := l.OriginalPos(tokens[i].Pos); got != w.original {
+			t.Errorf("OriginalPos(%d) = %d, want %d", tokens[i].Pos, got, w.original)
+		}
+	}
+	if got := l.OriginalPos(2); got != 0 {
+		t.Errorf("position within tab maps to %d, want 0", got)
+	}
+}
+
+func TestExpandTabsBaseOffset(t *testing.T) {
+	l := lex.LexString("\tx", lexFields, lex.ExpandTabs(8), lex.BaseOffset(100))
+	tokens := collect(l)
+	if tokens[0].Pos != 108 || l.OriginalPos(tokens[0].Pos) != 101 {
+		t.Errorf("got %q at %d, original %d", tokens[0].Val, tokens[0].Pos, l.OriginalPos(tokens[0].Pos))
+	}
+	if got := l.OriginalPos(0); got != 100 {
+		t.Errorf("OriginalPos(0) = %d, want the base offset 100", got)
+	}
+}
+
+func TestExpandTabsMaxInputBytes(t *testing.T) {
+	if tokens := collect(lex.LexString("\t\tab", lexFields, lex.ExpandTabs(8), lex.MaxInputBytes(5))); tokens[0].Val != "ab" || tokens[1].Typ != lex.TokEOF {
+		t.Errorf("got %v, want ab within the limit of the original input", tokens)
+	}
+	tokens := collect(lex.LexString("\t\tab", lexFields, lex.ExpandTabs(8), lex.MaxInputBytes(3)))
+	if last := tokens[len(tokens)-1]; last.Typ != lex.TokError || last.Val != "input too large" || len(tokens) != 1 {
+		t.Errorf("got %v, want input too large", tokens)
+	}
+}
@@ -0,0 +1,96 @@
+package lex
+
+import "strings"
+
+// TemplateTypes are the token types ScanTemplate emits
+type TemplateTypes struct {
+	Text        TokenType // Literal text between actions
+	ActionStart TokenType // Left delimiter with its trim marker, if any
+	Action      TokenType // Body of an action between the delimiters and trim markers
+	ActionEnd   TokenType // Right delimiter with its trim marker, if any
+}
+
+// isTemplateSpace reports whether r is a space trim markers strip,
+// as text/template defines it
+func isTemplateSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+// ScanTemplate emits tokens of the template up to the end of the input: Text
+// for literal spans and ActionStart, Action and ActionEnd for actions enclosed
+// in left and right delimiters, such as "{{" and "}}". As in text/template,
+// "{{- " trims spaces preceding the action off the text and " -}}" trims
+// spaces following it; quoted strings inside actions may contain the delimiters.
+// Empty text and action bodies are not emitted.
+// It returns false for an unterminated action or a string inside it
+func ScanTemplate(l *Lexer, left, right string, types TemplateTypes) bool {
+	trimLeading := false
+	for {
+		if trimLeading {
+			l.IgnoreRunes(isTemplateSpace)
+		}
+		i := strings.Index(l.input[l.pos:], left)
+		if i < 0 {
+			l.AcceptUntil()
+			emitNonEmpty(l, types.Text)
+			return true
+		}
+		l.advance(l.pos + Pos(i))
+		after := l.input[l.pos+Pos(len(left)):]
+		trimTrailing := len(after) > 1 && after[0] == '-' && isTemplateSpace(rune(after[1]))
+		if trimTrailing {
+			delim := l.pos
+			for l.pos > l.start && isTemplateSpace(rune(l.input[l.pos-1])) {
+				l.pos--
+			}
+			emitNonEmpty(l, types.Text)
+			l.pos = delim
+			l.Ignore()
+		} else {
+			emitNonEmpty(l, types.Text)
+		}
+		l.AcceptString(left)
+		if trimTrailing {
+			l.Accept('-')
+		}
+		l.Emit(types.ActionStart)
+
+		for {
+			rest := l.input[l.pos:]
+			trimLeading = len(rest) > 1 && isTemplateSpace(rune(rest[0])) && rest[1] == '-' &&
+				strings.HasPrefix(rest[2:], right)
+			if trimLeading {
+				l.Next()
+				break
+			}
+			if strings.HasPrefix(rest, right) {
+				break
+			}
+			switch r := l.Next(); r {
+			case eof:
+				return false
+			case '"', '\'':
+				if !skipLiteral(l, r, '\\') {
+					return false
+				}
+			case '`':
+				if !skipLiteral(l, r, 0) {
+					return false
+				}
+			}
+		}
+		emitNonEmpty(l, types.Action)
+		if trimLeading {
+			l.Accept('-')
+		}
+		l.AcceptString(right)
+		l.Emit(types.ActionEnd)
+	}
+}
+
+// emitNonEmpty emits the pending input unless it is empty
+func emitNonEmpty(l *Lexer, t TokenType) {
+	if l.pos > l.start {
+		l.Emit(t)
+	}
+}
@@ -0,0 +1,74 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+var templateTypes = lex.TemplateTypes{
+	Text:        tokText,
+	ActionStart: tokActionStart,
+	Action:      tokAction,
+	ActionEnd:   tokActionEnd,
+}
+
+func TestScanTemplate(t *testing.T) {
+	type tok struct {
+		typ lex.TokenType
+		val string
+	}
+	tests := []struct {
+		name        string
+		input       string
+		left, right string
+		ok          bool
+		want        []tok
+	}{
+		{"plain", "Hello, {{ .Name }}!", "{{", "}}", true, []tok{
+			{tokText, "Hello, "}, {tokActionStart, "{{"}, {tokAction, " .Name "}, {tokActionEnd, "}}"}, {tokText, "!"},
+		}},
+		{"trim markers", "a \n {{- .X -}} \t b", "{{", "}}", true, []tok{
+			{tokText, "a"}, {tokActionStart, "{{-"}, {tokAction, " .X "}, {tokActionEnd, "-}}"}, {tokText, "b"},
+		}},
+		{"minus without space", "a {{-3}} b", "{{", "}}", true, []tok{
+			{tokText, "a "}, {tokActionStart, "{{"}, {tokAction, "-3"}, {tokActionEnd, "}}"}, {tokText, " b"},
+		}},
+		{"delimiters in strings", "x{{ printf \"}}%s\" `{{` '}' }}y", "{{", "}}", true, []tok{
+			{tokText, "x"}, {tokActionStart, "{{"}, {tokAction, " printf \"}}%s\" `{{` '}' "}, {tokActionEnd, "}}"}, {tokText, "y"},
+		}},
+		{"adjacent actions", "{{a}}{{}}", "{{", "}}", true, []tok{
+			{tokActionStart, "{{"}, {tokAction, "a"}, {tokActionEnd, "}}"}, {tokActionStart, "{{"}, {tokActionEnd, "}}"},
+		}},
+		{"custom delimiters", "<p><%= x %></p>", "<%=", "%>", true, []tok{
+			{tokText, "<p>"}, {tokActionStart, "<%="}, {tokAction, " x "}, {tokActionEnd, "%>"}, {tokText, "</p>"},
+		}},
+		{"unterminated action", "a {{ .X", "{{", "}}", false, []tok{
+			{tokText, "a "}, {tokActionStart, "{{"},
+		}},
+		{"unterminated string", `{{ "}} }}`, "{{", "}}", false, []tok{
+			{tokActionStart, "{{"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ok bool
+			tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+				ok = lex.ScanTemplate(l, tt.left, tt.right, templateTypes)
+				return lex.EOF
+			}))
+			if ok != tt.ok {
+				t.Errorf("ScanTemplate() = %v, want %v", ok, tt.ok)
+			}
+			tokens = tokens[:len(tokens)-1]
+			if len(tokens) != len(tt.want) {
+				t.Fatalf("got %v, want %v", tokens, tt.want)
+			}
+			for i, w := range tt.want {
+				if tokens[i].Typ != w.typ || tokens[i].Val != w.val {
+					t.Errorf("token %d = %q of type %d, want %q of type %d", i, tokens[i].Val, tokens[i].Typ, w.val, w.typ)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package lex
+
+// trieNode is a node of a trie keyed by runes
+type trieNode struct {
+	next map[rune]*trieNode
+	typ  TokenType
+	end  bool // a word ends at the node
+}
+
+// add inserts the word of type typ below the node
+func (n *trieNode) add(word string, typ TokenType) {
+	for _, r := range word {
+		child := n.next[r]
+		if child == nil {
+			if n.next == nil {
+				n.next = make(map[rune]*trieNode)
+			}
+			child = &trieNode{}
+			n.next[r] = child
+		}
+		n = child
+	}
+	n.typ, n.end = typ, true
+}
+
+// KeywordTrie is a set of keywords with their token types.
+// The zero value is an empty set ready to use
+type KeywordTrie struct {
+	root trieNode
+}
+
+// NewKeywordTrie creates a trie of the keywords
+func NewKeywordTrie(keywords map[string]TokenType) *KeywordTrie {
+	trie := &KeywordTrie{}
+	for keyword, typ := range keywords {
+		trie.Add(keyword, typ)
+	}
+	return trie
+}
+
+// Add inserts the keyword of type typ
+func (t *KeywordTrie) Add(keyword string, typ TokenType) {
+	t.root.add(keyword, typ)
+}
+
+// ScanKeywordOrIdent consumes a run of runes for which identCont returns true
+// and emits it as a keyword when the run exactly matches one of the trie,
+// otherwise as a token of identType. The trie is walked along with the scan,
+// so no lookups are made once the run deviates from all keywords.
+// It returns false when no runes were consumed
+func ScanKeywordOrIdent(l *Lexer, trie *KeywordTrie, identCont func(rune) bool, identType TokenType) bool {
+	node := &trie.root
+	for {
+		r := l.Next()
+		if r == eof || !identCont(r) {
+			l.Backup()
+			break
+		}
+		if node != nil {
+			node = node.next[r]
+		}
+	}
+	if l.pos == l.start {
+		return false
+	}
+	if node != nil && node.end {
+		l.Emit(node.typ)
+	} else {
+		l.Emit(identType)
+	}
+	return true
+}
+
+// PunctuationTable maps operators and punctuation to their token types
+type PunctuationTable struct {
+	root trieNode
+}
+
+// NewPunctuationTable compiles the operators into a longest-match scanner
+func NewPunctuationTable(entries map[string]TokenType) *PunctuationTable {
+	tab := &PunctuationTable{}
+	for op, typ := range entries {
+		tab.root.add(op, typ)
+	}
+	return tab
+}
+
+// ScanPunctuation consumes and emits the longest operator of the table
+// at the current position and returns its type.
+// It returns false, consuming nothing, when no operator matches
+func ScanPunctuation(l *Lexer, tab *PunctuationTable) (TokenType, bool) {
+	var (
+		match *trieNode
+		end   = l.pos
+	)
+	for node := &tab.root; ; {
+		if node = node.next[l.Next()]; node == nil {
+			break
+		}
+		if node.end {
+			match, end = node, l.pos
+		}
+	}
+	l.pos, l.width = end, 0
+	if match == nil {
+		return 0, false
+	}
+	l.Emit(match.typ)
+	return match.typ, true
+}
@@ -0,0 +1,142 @@
+package lex_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/redsift/lex"
+)
+
+var keywords = map[string]lex.TokenType{"if": tokIf, "for": tokFor, "func": tokFunc}
+
+func identCont(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func TestScanKeywordOrIdent(t *testing.T) {
+	trie := lex.NewKeywordTrie(keywords)
+	tests := []struct {
+		input string
+		typ   lex.TokenType
+		val   string
+	}{
+		{"if(", tokIf, "if"},
+		{"func", tokFunc, "func"},
+		{"fo", tokIdent, "fo"},
+		{"fun", tokIdent, "fun"},
+		{"format", tokIdent, "format"},
+		{"iffy", tokIdent, "iffy"},
+		{"x_1 y", tokIdent, "x_1"},
+		{"для", tokIdent, "для"},
+	}
+	for _, tt := range tests {
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			if !lex.ScanKeywordOrIdent(l, trie, identCont, tokIdent) {
+				t.Errorf("%q: nothing scanned", tt.input)
+			}
+			return lex.EOF
+		}))
+		if tokens[0].Typ != tt.typ || tokens[0].Val != tt.val {
+			t.Errorf("%q: got %v of type %d, want %q of type %d", tt.input, tokens[0], tokens[0].Typ, tt.val, tt.typ)
+		}
+	}
+
+	collect(lex.LexString("(", func(l *lex.Lexer) lex.StateFn {
+		if lex.ScanKeywordOrIdent(l, trie, identCont, tokIdent) {
+			t.Error("scanned non-identifier")
+		}
+		return lex.EOF
+	}))
+}
+
+var identifiers = strings.Repeat("if iffy format for func function x ", 100)
+
+func BenchmarkScanKeywordOrIdent(b *testing.B) {
+	trie := lex.NewKeywordTrie(keywords)
+	var state lex.StateFn
+	state = func(l *lex.Lexer) lex.StateFn {
+		l.IgnoreRunes(unicode.IsSpace)
+		if !lex.ScanKeywordOrIdent(l, trie, identCont, tokIdent) {
+			return lex.EOF
+		}
+		return state
+	}
+	for i := 0; i < b.N; i++ {
+		collect(lex.LexString(identifiers, state))
+	}
+}
+
+func BenchmarkKeywordMap(b *testing.B) {
+	var state lex.StateFn
+	state = func(l *lex.Lexer) lex.StateFn {
+		l.IgnoreRunes(unicode.IsSpace)
+		if l.CountRunFunc(identCont) == 0 {
+			return lex.EOF
+		}
+		if typ, ok := keywords[l.Current()]; ok {
+			l.Emit(typ)
+		} else {
+			l.Emit(tokIdent)
+		}
+		return state
+	}
+	for i := 0; i < b.N; i++ {
+		collect(lex.LexString(identifiers, state))
+	}
+}
+
+func TestScanPunctuation(t *testing.T) {
+	const (
+		tokLess = tokFunc + 1 + iota
+		tokLessEq
+		tokShl
+		tokShlAssign
+		tokArrow
+	)
+	tab := lex.NewPunctuationTable(map[string]lex.TokenType{
+		"<":   tokLess,
+		"<=":  tokLessEq,
+		"<<":  tokShl,
+		"<<=": tokShlAssign,
+		"<-":  tokArrow,
+	})
+	tests := []struct {
+		input string
+		typ   lex.TokenType
+		val   string
+		ok    bool
+	}{
+		{"<", tokLess, "<", true},
+		{"< b", tokLess, "<", true},
+		{"<=b", tokLessEq, "<=", true},
+		{"<<", tokShl, "<<", true},
+		{"<<=1", tokShlAssign, "<<=", true},
+		{"<<-", tokShl, "<<", true},
+		{"<-ch", tokArrow, "<-", true},
+		{"+", 0, "", false},
+		{"", 0, "", false},
+	}
+	for _, tt := range tests {
+		var (
+			typ lex.TokenType
+			ok  bool
+		)
+		tokens := collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			typ, ok = lex.ScanPunctuation(l, tab)
+			return lex.EOF
+		}))
+		if typ != tt.typ || ok != tt.ok {
+			t.Errorf("%q: got (%d, %v), want (%d, %v)", tt.input, typ, ok, tt.typ, tt.ok)
+		}
+		if !tt.ok {
+			if tokens[0].Typ != lex.TokEOF || tokens[0].Pos != 0 {
+				t.Errorf("%q: consumed input before %v", tt.input, tokens[0])
+			}
+			continue
+		}
+		if tokens[0].Typ != tt.typ || tokens[0].Val != tt.val {
+			t.Errorf("%q: emitted %v, want %q", tt.input, tokens[0], tt.val)
+		}
+	}
+}
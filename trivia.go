@@ -0,0 +1,46 @@
+package lex
+
+import "strings"
+
+// TriviaToken is a significant token with the trivia, such as comments, attached to it
+type TriviaToken struct {
+	Token
+	Leading  []Token // Trivia preceding the token
+	Trailing []Token // Trivia following the token on the line it ends on
+}
+
+// AttachTrivia attaches trivia tokens, those of types for which isTrivia
+// returns true, to significant tokens. Trivia starting on the line a significant
+// token ends on, such as a trailing "// comment", is trailing for that token,
+// up to the first trivia containing a newline; any other trivia leads the next
+// significant token, or trails the last one when none follows.
+// Positions of tokens must refer to input
+func AttachTrivia(input string, tokens []Token, isTrivia func(TokenType) bool) []TriviaToken {
+	var (
+		result  []TriviaToken
+		pending []Token
+		end     Pos  // end of the last significant token or its trailing trivia
+		ended   bool // whether trailing trivia of the last significant token ended its line
+	)
+	for _, tok := range tokens {
+		if !isTrivia(tok.Typ) {
+			result = append(result, TriviaToken{Token: tok, Leading: pending})
+			pending = nil
+			end, ended = tok.Pos+Pos(len(tok.Val)), false
+			continue
+		}
+		if len(result) > 0 && len(pending) == 0 && !ended && tok.Pos >= end &&
+			!strings.ContainsRune(input[end:tok.Pos], '\n') {
+			last := &result[len(result)-1]
+			last.Trailing = append(last.Trailing, tok)
+			end, ended = tok.Pos+Pos(len(tok.Val)), strings.ContainsRune(tok.Val, '\n')
+			continue
+		}
+		pending = append(pending, tok)
+	}
+	if len(pending) > 0 && len(result) > 0 {
+		last := &result[len(result)-1]
+		last.Trailing = append(last.Trailing, pending...)
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package lex_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/redsift/lex"
+)
+
+// lexCode emits words and comments from a slash to the end of the line ignoring spaces
+func lexCode(l *lex.Lexer) lex.StateFn {
+	l.IgnoreRunes(unicode.IsSpace)
+	switch {
+	case l.Peek() == '/':
+		l.AcceptUntil('\n')
+		l.Emit(tokComment)
+	case l.CountRunFunc(func(r rune) bool { return !unicode.IsSpace(r) }) > 0:
+		l.Emit(tokWord)
+	default:
+		return lex.EOF
+	}
+	return lexCode
+}
+
+func TestAttachTrivia(t *testing.T) {
+	input := `// header
+a // trailing a
+// leading b
+b c // trailing c
+  // leading EOF
+`
+	nodes := lex.AttachTrivia(input, lex.LexAll(input, lexCode), func(t lex.TokenType) bool {
+		return t == tokComment
+	})
+	vals := func(tokens []lex.Token) []string {
+		var vals []string
+		for _, tok := range tokens {
+			vals = append(vals, tok.Val)
+		}
+		return vals
+	}
+	want := []struct {
+		val      string
+		leading  []string
+		trailing []string
+	}{
+		{"a", []string{"// header"}, []string{"// trailing a"}},
+		{"b", []string{"// leading b"}, nil},
+		{"c", nil, []string{"// trailing c"}},
+		{"", []string{"// leading EOF"}, nil},
+	}
+	if len(nodes) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(nodes), len(want))
+	}
+	for i, w := range want {
+		n := nodes[i]
+		if n.Val != w.val {
+			t.Errorf("token %d = %q, want %q", i, n.Val, w.val)
+		}
+		if got := vals(n.Leading); strings.Join(got, "|") != strings.Join(w.leading, "|") {
+			t.Errorf("%q: leading %q, want %q", n.Val, got, w.leading)
+		}
+		if got := vals(n.Trailing); strings.Join(got, "|") != strings.Join(w.trailing, "|") {
+			t.Errorf("%q: trailing %q, want %q", n.Val, got, w.trailing)
+		}
+	}
+}
+
+// lexWhitespace emits words, comments and runs of spaces and newlines
+func lexWhitespace(l *lex.Lexer) lex.StateFn {
+	switch r := l.Peek(); {
+	case r == lex.EOFRune:
+		return lex.EOF
+	case r == '\n':
+		l.AcceptRun('\n')
+		l.Emit(tokSpace)
+	case r == ' ':
+		l.AcceptRun(' ')
+		l.Emit(tokSpace)
+	case r == '/':
+		l.AcceptUntil('\n')
+		l.Emit(tokComment)
+	default:
+		l.AcceptUntil(' ', '\n')
+		l.Emit(tokWord)
+	}
+	return lexWhitespace
+}
+
+func TestAttachTriviaWhitespace(t *testing.T) {
+	input := "a // trailing\n// leading\nb c\n\n  d"
+	nodes := lex.AttachTrivia(input, lex.LexAll(input, lexWhitespace), func(t lex.TokenType) bool {
+		return t == tokComment || t == tokSpace
+	})
+	want := []struct {
+		val      string
+		leading  string
+		trailing string
+	}{
+		{"a", "", " |// trailing|\n"},
+		{"b", "// leading|\n", " "},
+		{"c", "", "\n\n"},
+		{"d", "  ", ""},
+		{"", "", ""},
+	}
+	join := func(tokens []lex.Token) string {
+		var vals []string
+		for _, tok := range tokens {
+			vals = append(vals, tok.Val)
+		}
+		return strings.Join(vals, "|")
+	}
+	if len(nodes) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(nodes), len(want))
+	}
+	for i, w := range want {
+		n := nodes[i]
+		if n.Val != w.val || join(n.Leading) != w.leading || join(n.Trailing) != w.trailing {
+			t.Errorf("token %d = %q leading %q trailing %q, want %q leading %q trailing %q",
+				i, n.Val, join(n.Leading), join(n.Trailing), w.val, w.leading, w.trailing)
+		}
+	}
+}
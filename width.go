@@ -0,0 +1,63 @@
+package lex
+
+import "unicode"
+
+// wide lists East Asian Wide and Fullwidth runes occupying two terminal cells
+var wide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115f, 1}, // Hangul Jamo initial consonants
+		{0x2e80, 0x303e, 1}, // CJK radicals, symbols and punctuation
+		{0x3041, 0x33ff, 1}, // Hiragana, Katakana, Bopomofo, CJK compatibility
+		{0x3400, 0x4dbf, 1}, // CJK unified ideographs extension A
+		{0x4e00, 0x9fff, 1}, // CJK unified ideographs
+		{0xa000, 0xa4cf, 1}, // Yi
+		{0xa960, 0xa97f, 1}, // Hangul Jamo extended A
+		{0xac00, 0xd7a3, 1}, // Hangul syllables
+		{0xf900, 0xfaff, 1}, // CJK compatibility ideographs
+		{0xfe10, 0xfe19, 1}, // vertical forms
+		{0xfe30, 0xfe6f, 1}, // CJK compatibility forms, small form variants
+		{0xff00, 0xff60, 1}, // fullwidth forms
+		{0xffe0, 0xffe6, 1}, // fullwidth signs
+	},
+	R32: []unicode.Range32{
+		{0x1f300, 0x1f64f, 1}, // pictographs and emoticons
+		{0x1f900, 0x1f9ff, 1}, // supplemental pictographs
+		{0x20000, 0x2fffd, 1}, // CJK extensions B to F
+		{0x30000, 0x3fffd, 1}, // CJK extension G
+	},
+}
+
+// RuneDisplayWidth returns the number of terminal cells r occupies:
+// 0 for combining marks, format and control characters,
+// 2 for East Asian Wide and Fullwidth runes and 1 for the others
+func RuneDisplayWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc):
+		return 0
+	case unicode.Is(wide, r):
+		return 2
+	}
+	return 1
+}
+
+// DisplayWidthFunc replaces RuneDisplayWidth used by CurrentDisplayWidth, e.g.
+// with a function based on golang.org/x/text/width
+func DisplayWidthFunc(width func(rune) int) Option {
+	return func(l *Lexer) {
+		l.runeWidth = width
+	}
+}
+
+// CurrentDisplayWidth returns the number of terminal cells the pending input
+// of the current token occupies
+func (l *Lexer) CurrentDisplayWidth() int {
+	width := l.runeWidth
+	if width == nil {
+		width = RuneDisplayWidth
+	}
+	n := 0
+	for _, r := range l.Current() {
+		n += width(r)
+	}
+	return n
+}
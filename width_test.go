@@ -0,0 +1,36 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/redsift/lex"
+)
+
+func TestCurrentDisplayWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []lex.Option
+		want  int
+	}{
+		{"ascii", "hello", nil, 5},
+		{"cjk", "日本語", nil, 6},
+		{"mixed", "a日b", nil, 4},
+		{"fullwidth", "ＡＢ", nil, 4},
+		{"hangul", "한국", nil, 4},
+		{"combining", "e\u0301e\u0301", nil, 2},
+		{"empty", "", nil, 0},
+		{"custom", "日本", []lex.Option{lex.DisplayWidthFunc(func(rune) int { return 1 })}, 2},
+	}
+	for _, tt := range tests {
+		var got int
+		collect(lex.LexString(tt.input, func(l *lex.Lexer) lex.StateFn {
+			l.AcceptUntil()
+			got = l.CurrentDisplayWidth()
+			return lex.EOF
+		}, tt.opts...))
+		if got != tt.want {
+			t.Errorf("%s: CurrentDisplayWidth() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}